@@ -0,0 +1,75 @@
+package main
+
+import (
+	"log"
+	"math"
+
+	e "github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// deadZone is how far a stick has to be pushed before it counts, so worn
+// sticks do not make the hero drift.
+const deadZone = 0.2
+
+var gamepads []e.GamepadID
+
+// handleGamepads keeps the list of connected gamepads up to date, so
+// controllers can be plugged in and out while the game runs.
+func handleGamepads() {
+	for _, id := range inpututil.AppendJustConnectedGamepadIDs(nil) {
+		log.Printf("gamepad %d connected: %s", id, e.GamepadName(id))
+		gamepads = append(gamepads, id)
+	}
+
+	connected := gamepads[:0]
+	for _, id := range gamepads {
+		if inpututil.IsGamepadJustDisconnected(id) {
+			log.Printf("gamepad %d disconnected", id)
+			continue
+		}
+		connected = append(connected, id)
+	}
+	gamepads = connected
+}
+
+// readGamepads adds what the standard gamepads ask for to the intent: the
+// D-pad and the left stick move, the bottom face button attacks and the
+// right one interacts. Gamepads without a standard layout are ignored.
+func readGamepads(intent *Intent) {
+	for _, id := range gamepads {
+		if !e.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+
+		x := e.StandardGamepadAxisValue(id, e.StandardGamepadAxisLeftStickHorizontal)
+		y := e.StandardGamepadAxisValue(id, e.StandardGamepadAxisLeftStickVertical)
+		if math.Hypot(x, y) < deadZone {
+			x, y = 0, 0
+		}
+		if e.IsStandardGamepadButtonPressed(id, e.StandardGamepadButtonLeftLeft) {
+			x = -1
+		}
+		if e.IsStandardGamepadButtonPressed(id, e.StandardGamepadButtonLeftRight) {
+			x = 1
+		}
+		if e.IsStandardGamepadButtonPressed(id, e.StandardGamepadButtonLeftTop) {
+			y = -1
+		}
+		if e.IsStandardGamepadButtonPressed(id, e.StandardGamepadButtonLeftBottom) {
+			y = 1
+		}
+		intent.add(x, y)
+
+		if e.IsStandardGamepadButtonPressed(id, e.StandardGamepadButtonRightBottom) {
+			intent.Attack = true
+		}
+		if e.IsStandardGamepadButtonPressed(id, e.StandardGamepadButtonRightRight) {
+			intent.Interact = true
+		}
+		// The first slot usually holds the healing flasks.
+		if e.IsStandardGamepadButtonPressed(id, e.StandardGamepadButtonRightTop) {
+			intent.Use = 1
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"example.com/game/internal"
+	e "github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// slotSize is the width and height of an inventory slot on screen.
+const slotSize = 24
+
+// drawHUD draws the health of the hero as a row of hearts, each worth two
+// points, and the respawn countdown while dead.
+func drawHUD(screen *e.Image) {
+	me := world.Snapshot().Units[world.MyID]
+	if me == nil {
+		return
+	}
+
+	for i := int32(0); i*2 < me.MaxHealth; i++ {
+		heart := "ui_heart_empty"
+		if me.Health >= i*2+2 {
+			heart = "ui_heart_full"
+		} else if me.Health == i*2+1 {
+			heart = "ui_heart_half"
+		}
+
+		op := &e.DrawImageOptions{}
+		op.GeoM.Translate(float64(4+i*18), 18)
+		screen.DrawImage(e.NewImageFromImage(frames[heart].Frames[0]), op)
+	}
+
+	if me.Action == internal.UnitActionDead {
+		seconds := (me.Respawn + internal.TickRate - 1) / internal.TickRate
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("You died. Respawning in %d...", seconds), 4, 38)
+	}
+
+	drawInventory(screen, me)
+}
+
+// slotRect returns where an inventory slot is drawn: in a row at the
+// bottom middle of a screen of the given size.
+func slotRect(slot int, width, height float64) image.Rectangle {
+	x := int(width)/2 - internal.InventorySize*slotSize/2 + slot*slotSize
+	y := int(height) - slotSize - 8
+	return image.Rect(x, y, x+slotSize, y+slotSize)
+}
+
+// slotAt returns the inventory slot at a point of the screen, or -1.
+func slotAt(x, y int, width, height float64) int {
+	for slot := 0; slot < internal.InventorySize; slot++ {
+		if image.Pt(x, y).In(slotRect(slot, width, height)) {
+			return slot
+		}
+	}
+	return -1
+}
+
+// drawInventory draws the slots of the inventory with what they hold and
+// how many of it.
+func drawInventory(screen *e.Image, me *internal.Unit) {
+	bounds := screen.Bounds()
+	width, height := float64(bounds.Dx()), float64(bounds.Dy())
+
+	for slot := 0; slot < internal.InventorySize; slot++ {
+		rect := slotRect(slot, width, height)
+		x, y := float32(rect.Min.X), float32(rect.Min.Y)
+		vector.DrawFilledRect(screen, x, y, slotSize, slotSize, color.RGBA{0, 0, 0, 0x80}, false)
+		vector.StrokeRect(screen, x, y, slotSize, slotSize, 1, color.RGBA{0xff, 0xff, 0xff, 0x60}, false)
+		if slot >= len(me.Inventory) {
+			continue
+		}
+
+		item := me.Inventory[slot]
+		sprite, ok := frames[item.Item]
+		if !ok {
+			continue
+		}
+		op := &e.DrawImageOptions{}
+		op.GeoM.Translate(
+			float64(rect.Min.X)+float64(slotSize-sprite.Config.Width)/2,
+			float64(rect.Min.Y)+float64(slotSize-sprite.Config.Height)/2,
+		)
+		screen.DrawImage(e.NewImageFromImage(sprite.Frames[0]), op)
+		if item.Count > 1 {
+			ebitenutil.DebugPrintAt(screen, fmt.Sprint(item.Count), rect.Min.X+1, rect.Max.Y-14)
+		}
+	}
+}
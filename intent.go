@@ -0,0 +1,22 @@
+package main
+
+import "math"
+
+// Intent is what the player asks for during a tick, whichever device it
+// comes from.
+type Intent struct {
+	X        float64
+	Y        float64
+	Attack   bool
+	Interact bool
+	// Use is the inventory slot to use, counted from 1; 0 uses none.
+	Use int
+}
+
+// add combines a movement from one more device into the intent. Analog
+// values are rounded so a resting thumb does not produce a stream of
+// slightly different moves.
+func (intent *Intent) add(x, y float64) {
+	intent.X = math.Max(-1, math.Min(1, intent.X+math.Round(x*10)/10))
+	intent.Y = math.Max(-1, math.Min(1, intent.Y+math.Round(y*10)/10))
+}
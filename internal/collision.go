@@ -0,0 +1,235 @@
+package internal
+
+import (
+	"bytes"
+	"image"
+	_ "image/png"
+	"math"
+	"path/filepath"
+)
+
+// box is an axis-aligned rectangle in world pixels.
+type box struct {
+	x0 float64
+	y0 float64
+	x1 float64
+	y1 float64
+}
+
+// LoadFootprints reads the sprite size of every player and monster skin and
+// derives the part of it that collides with the level: the feet, in the
+// lower quarter of the sprite, slightly narrower than the sprite itself.
+func LoadFootprints() (map[string]image.Rectangle, error) {
+	skins := append([]string{}, PlayerSkins...)
+	for skin := range Monsters {
+		skins = append(skins, skin)
+	}
+
+	footprints := map[string]image.Rectangle{}
+	for _, skin := range skins {
+		sprite := skin + "_idle"
+		if alias, ok := SkinSprites[skin]; ok {
+			sprite = alias
+		}
+		fileBytes, err := readFile(filepath.Join("asset", "sprites", sprite+"_anim_f0.png"))
+		if err != nil {
+			return nil, err
+		}
+
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(fileBytes))
+		if err != nil {
+			return nil, err
+		}
+
+		inset := cfg.Width / 8
+		footprints[skin] = image.Rect(inset, cfg.Height-cfg.Height/4, cfg.Width-inset, cfg.Height)
+	}
+
+	return footprints, nil
+}
+
+// footprint returns the colliding box of the unit at its current position.
+func (world *World) footprint(unit *Unit) box {
+	rect := world.Footprints[unit.Skin]
+	return box{
+		x0: unit.X + float64(rect.Min.X),
+		y0: unit.Y + float64(rect.Min.Y),
+		x1: unit.X + float64(rect.Max.X),
+		y1: unit.Y + float64(rect.Max.Y),
+	}
+}
+
+// move shifts the unit by dx and dy, one axis after the other, so a unit
+// running into a wall at an angle still slides along it.
+func (world *World) move(unit *Unit, dx, dy float64) {
+	if world.Level == nil {
+		unit.X += dx
+		unit.Y += dy
+		return
+	}
+
+	unit.X += world.clip(world.footprint(unit), dx, 0)
+	unit.Y += world.clip(world.footprint(unit), 0, dy)
+}
+
+// clip shortens a move of b along one axis so that it stops at the first
+// solid tile, and returns the distance that can actually be covered.
+func (world *World) clip(b box, dx, dy float64) float64 {
+	// Moves are checked in steps shorter than a tile so nothing is skipped.
+	const step = TileSize / 2
+	if world.blocked(b) {
+		// Let units that are stuck in a wall walk out of it, but never
+		// further in.
+		next := b
+		next.x0 += dx
+		next.x1 += dx
+		next.y0 += dy
+		next.y1 += dy
+		if world.overlap(next) < world.overlap(b) {
+			return dx + dy
+		}
+		return 0
+	}
+
+	moved := 0.0
+	total := math.Abs(dx + dy)
+	sign := math.Copysign(1, dx+dy)
+
+	for moved < total {
+		d := math.Min(step, total-moved)
+		next := b
+		if dx != 0 {
+			next.x0 += sign * (moved + d)
+			next.x1 += sign * (moved + d)
+		} else {
+			next.y0 += sign * (moved + d)
+			next.y1 += sign * (moved + d)
+		}
+
+		if !world.blocked(next) {
+			moved += d
+			continue
+		}
+
+		// Stop flush against the edge of the tile that was hit.
+		switch {
+		case dx > 0:
+			return math.Max(0, (math.Ceil(next.x1/TileSize)-1)*TileSize-b.x1)
+		case dx < 0:
+			return math.Min(0, (math.Floor(next.x0/TileSize)+1)*TileSize-b.x0)
+		case dy > 0:
+			return math.Max(0, (math.Ceil(next.y1/TileSize)-1)*TileSize-b.y1)
+		default:
+			return math.Min(0, (math.Floor(next.y0/TileSize)+1)*TileSize-b.y0)
+		}
+	}
+
+	return sign * moved
+}
+
+// blocked reports whether the box overlaps a solid tile of the level or a
+// closed door.
+func (world *World) blocked(b box) bool {
+	// The far edges are exclusive: a box may touch a wall.
+	i0 := int(math.Floor(b.x0 / TileSize))
+	j0 := int(math.Floor(b.y0 / TileSize))
+	i1 := int(math.Ceil(b.x1/TileSize)) - 1
+	j1 := int(math.Ceil(b.y1/TileSize)) - 1
+
+	for i := i0; i <= i1; i++ {
+		for j := j0; j <= j1; j++ {
+			if world.solid(i, j) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// overlap returns the area of the box that lies on solid tiles.
+func (world *World) overlap(b box) float64 {
+	i0 := int(math.Floor(b.x0 / TileSize))
+	j0 := int(math.Floor(b.y0 / TileSize))
+	i1 := int(math.Ceil(b.x1/TileSize)) - 1
+	j1 := int(math.Ceil(b.y1/TileSize)) - 1
+
+	area := 0.0
+	for i := i0; i <= i1; i++ {
+		for j := j0; j <= j1; j++ {
+			if !world.solid(i, j) {
+				continue
+			}
+			w := math.Min(b.x1, float64(i+1)*TileSize) - math.Max(b.x0, float64(i)*TileSize)
+			h := math.Min(b.y1, float64(j+1)*TileSize) - math.Max(b.y0, float64(j)*TileSize)
+			area += w * h
+		}
+	}
+
+	return area
+}
+
+// shape sets the collider of a unit from its skin: a circle around its
+// feet as wide as its footprint, and the mass of the skin.
+func (world *World) shape(unit *Unit) {
+	rect := world.Footprints[unit.Skin]
+	unit.Radius = float64(rect.Dx()) / 2
+	unit.Mass = 1
+	if mass, ok := SkinMass[unit.Skin]; ok {
+		unit.Mass = mass
+	}
+}
+
+// center returns the middle of the unit's collider.
+func (world *World) center(unit *Unit) (float64, float64) {
+	b := world.footprint(unit)
+	return (b.x0 + b.x1) / 2, (b.y0 + b.y1) / 2
+}
+
+// separate pushes overlapping units apart. The overlap is shared in
+// inverse proportion to mass, so heavy units shove light ones aside. A
+// replica only knows where remote units were, so it only moves the local
+// player and leaves the rest to the server.
+func (world *World) separate() {
+	// Colliders are searched around the sprite origin, so the radius has
+	// to cover the distance from the origin to the feet as well.
+	const reach = 64
+
+	grid := NewGrid(world.Units, reach)
+	for _, id := range sortedIDs(world.Units) {
+		a := world.Units[id]
+		if a.Radius == 0 || dead(a) {
+			continue
+		}
+
+		for _, b := range grid.Near(a.X, a.Y, reach) {
+			if b.Id <= a.Id || b.Radius == 0 || dead(b) {
+				continue
+			}
+			if world.Replica && a.Id != world.MyID && b.Id != world.MyID {
+				continue
+			}
+
+			ax, ay := world.center(a)
+			bx, by := world.center(b)
+			dx, dy := bx-ax, by-ay
+			dist := math.Hypot(dx, dy)
+			overlap := a.Radius + b.Radius - dist
+			if overlap <= 0 {
+				continue
+			}
+			if dist == 0 {
+				dx, dy, dist = 1, 0, 1
+			}
+			nx, ny := dx/dist, dy/dist
+
+			share := b.Mass / (a.Mass + b.Mass)
+			if !world.Replica || a.Id == world.MyID {
+				world.move(a, -nx*overlap*share, -ny*overlap*share)
+			}
+			if !world.Replica || b.Id == world.MyID {
+				world.move(b, nx*overlap*(1-share), ny*overlap*(1-share))
+			}
+		}
+	}
+}
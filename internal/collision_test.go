@@ -0,0 +1,49 @@
+package internal
+
+import "testing"
+
+// TestStuckInWall checks that a unit overlapping a wall can walk out of it
+// but not further in, nor through it.
+func TestStuckInWall(t *testing.T) {
+	world := &World{
+		Units:      map[string]*Unit{},
+		Footprints: testFootprints(),
+		Level: testLevel(
+			"######",
+			"#....#",
+			"#....#",
+			"######",
+		),
+	}
+	// The feet span x 2 to 14 from the unit, so this sinks 6px into the
+	// left wall.
+	unit := &Unit{Skin: PlayerSkins[0], X: TileSize - 8, Y: TileSize}
+	before := world.overlap(world.footprint(unit))
+	if before == 0 {
+		t.Fatal("unit is not in the wall")
+	}
+
+	for i := 0; i < 100; i++ {
+		world.move(unit, -1, 0)
+	}
+	if got := world.overlap(world.footprint(unit)); got > before {
+		t.Errorf("walking into the wall: overlap %v, was %v", got, before)
+	}
+	if unit.X < TileSize-8 {
+		t.Errorf("walking into the wall: x %v, was %v", unit.X, TileSize-8)
+	}
+
+	for i := 0; i < 100; i++ {
+		world.move(unit, 0, 1)
+	}
+	if got := world.overlap(world.footprint(unit)); got > before {
+		t.Errorf("walking along the wall: overlap %v, was %v", got, before)
+	}
+
+	for i := 0; i < 10; i++ {
+		world.move(unit, 1, 0)
+	}
+	if world.blocked(world.footprint(unit)) {
+		t.Errorf("unit did not walk out of the wall: at %v, %v", unit.X, unit.Y)
+	}
+}
@@ -0,0 +1,282 @@
+package internal
+
+import "github.com/golang/protobuf/proto"
+
+// maxBaselines bounds the snapshots a replica keeps for decoding deltas.
+const maxBaselines = 64
+
+// Bits of UnitDelta.Fields telling which fields carry a new value.
+const (
+	deltaX uint32 = 1 << iota
+	deltaY
+	deltaAction
+	deltaMove
+	deltaSide
+	deltaSequence
+	deltaInputTicks
+	deltaWeapon
+	deltaSwing
+	deltaStun
+	deltaHealth
+	deltaRespawn
+	deltaInventory
+	deltaEffects
+)
+
+// Diff encodes next relative to base. Units that did not change are left
+// out and changed units only carry the fields that differ. Units that
+// entered the snapshot are sent whole and units that left it are listed.
+// The input acknowledgement fields, the respawn countdown and the inventory
+// are only of use to the owner of a unit, so they are only sent for owner's
+// unit. Items never change, so they are only listed when they appear or
+// disappear; objects are small and sent whole when they change.
+func Diff(base, next *EventSnapshot, owner string) *EventDelta {
+	delta := &EventDelta{Tick: next.Tick, Base: base.Tick}
+
+	for _, id := range sortedIDs(next.Units) {
+		unit := next.Units[id]
+		old, ok := base.Units[id]
+		if !ok {
+			delta.Entered = append(delta.Entered, unit)
+			continue
+		}
+
+		change := &UnitDelta{Id: id}
+		if unit.X != old.X {
+			change.Fields |= deltaX
+			change.X = unit.X
+		}
+		if unit.Y != old.Y {
+			change.Fields |= deltaY
+			change.Y = unit.Y
+		}
+		if unit.Action != old.Action {
+			change.Fields |= deltaAction
+			change.Action = unit.Action
+		}
+		if unit.MoveX != old.MoveX || unit.MoveY != old.MoveY {
+			change.Fields |= deltaMove
+			change.MoveX = unit.MoveX
+			change.MoveY = unit.MoveY
+		}
+		if unit.Side != old.Side {
+			change.Fields |= deltaSide
+			change.Side = unit.Side
+		}
+		if unit.Weapon != old.Weapon {
+			change.Fields |= deltaWeapon
+			change.Weapon = unit.Weapon
+		}
+		if unit.Swing != old.Swing || unit.AimX != old.AimX || unit.AimY != old.AimY {
+			change.Fields |= deltaSwing
+			change.Swing = unit.Swing
+			change.AimX = unit.AimX
+			change.AimY = unit.AimY
+		}
+		if unit.Stun != old.Stun {
+			change.Fields |= deltaStun
+			change.Stun = unit.Stun
+		}
+		if unit.Health != old.Health || unit.MaxHealth != old.MaxHealth {
+			change.Fields |= deltaHealth
+			change.Health = unit.Health
+			change.MaxHealth = unit.MaxHealth
+		}
+		if id == owner && unit.Respawn != old.Respawn {
+			change.Fields |= deltaRespawn
+			change.Respawn = unit.Respawn
+		}
+		if id == owner && !sameInventory(unit.Inventory, old.Inventory) {
+			change.Fields |= deltaInventory
+			change.Inventory = unit.Inventory
+		}
+		if unit.Boost != old.Boost || unit.Shield != old.Shield {
+			change.Fields |= deltaEffects
+			change.Boost = unit.Boost
+			change.Shield = unit.Shield
+		}
+		if id == owner && unit.Sequence != old.Sequence {
+			change.Fields |= deltaSequence
+			change.Sequence = unit.Sequence
+		}
+		if id == owner && unit.InputTicks != old.InputTicks {
+			change.Fields |= deltaInputTicks
+			change.InputTicks = unit.InputTicks
+		}
+		if change.Fields != 0 {
+			delta.Changed = append(delta.Changed, change)
+		}
+	}
+
+	for _, id := range sortedIDs(base.Units) {
+		if _, ok := next.Units[id]; !ok {
+			delta.Left = append(delta.Left, id)
+		}
+	}
+
+	for id, item := range next.Items {
+		if _, ok := base.Items[id]; !ok {
+			delta.ItemsEntered = append(delta.ItemsEntered, item)
+		}
+	}
+	for id := range base.Items {
+		if _, ok := next.Items[id]; !ok {
+			delta.ItemsLeft = append(delta.ItemsLeft, id)
+		}
+	}
+
+	for id, object := range next.Objects {
+		if old, ok := base.Objects[id]; !ok || !proto.Equal(old, object) {
+			delta.Objects = append(delta.Objects, object)
+		}
+	}
+	for id := range base.Objects {
+		if _, ok := next.Objects[id]; !ok {
+			delta.ObjectsLeft = append(delta.ObjectsLeft, id)
+		}
+	}
+
+	return delta
+}
+
+// sameInventory reports whether two inventories hold the same items.
+func sameInventory(a, b []*Slot) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Item != b[i].Item || a[i].Count != b[i].Count {
+			return false
+		}
+	}
+	return true
+}
+
+// Patch rebuilds the snapshot delta was made from, given the snapshot it
+// was based on. The base is not modified; unchanged units are shared.
+func Patch(base *EventSnapshot, delta *EventDelta) *EventSnapshot {
+	next := &EventSnapshot{
+		Tick:  delta.Tick,
+		Units: make(map[string]*Unit, len(base.Units)+len(delta.Entered)),
+		Items: make(map[string]*Item, len(base.Items)+len(delta.ItemsEntered)),
+	}
+	for id, item := range base.Items {
+		next.Items[id] = item
+	}
+	for _, id := range delta.ItemsLeft {
+		delete(next.Items, id)
+	}
+	for _, item := range delta.ItemsEntered {
+		next.Items[item.Id] = item
+	}
+
+	next.Objects = make(map[string]*Object, len(base.Objects)+len(delta.Objects))
+	for id, object := range base.Objects {
+		next.Objects[id] = object
+	}
+	for _, id := range delta.ObjectsLeft {
+		delete(next.Objects, id)
+	}
+	for _, object := range delta.Objects {
+		next.Objects[object.Id] = object
+	}
+	for id, unit := range base.Units {
+		next.Units[id] = unit
+	}
+	for _, id := range delta.Left {
+		delete(next.Units, id)
+	}
+	for _, unit := range delta.Entered {
+		next.Units[unit.Id] = unit
+	}
+
+	for _, change := range delta.Changed {
+		old, ok := next.Units[change.Id]
+		if !ok {
+			continue
+		}
+
+		unit := proto.Clone(old).(*Unit)
+		if change.Fields&deltaX != 0 {
+			unit.X = change.X
+		}
+		if change.Fields&deltaY != 0 {
+			unit.Y = change.Y
+		}
+		if change.Fields&deltaAction != 0 {
+			unit.Action = change.Action
+		}
+		if change.Fields&deltaMove != 0 {
+			unit.MoveX = change.MoveX
+			unit.MoveY = change.MoveY
+		}
+		if change.Fields&deltaSide != 0 {
+			unit.Side = change.Side
+		}
+		if change.Fields&deltaWeapon != 0 {
+			unit.Weapon = change.Weapon
+		}
+		if change.Fields&deltaSwing != 0 {
+			unit.Swing = change.Swing
+			unit.AimX = change.AimX
+			unit.AimY = change.AimY
+		}
+		if change.Fields&deltaStun != 0 {
+			unit.Stun = change.Stun
+		}
+		if change.Fields&deltaHealth != 0 {
+			unit.Health = change.Health
+			unit.MaxHealth = change.MaxHealth
+		}
+		if change.Fields&deltaRespawn != 0 {
+			unit.Respawn = change.Respawn
+		}
+		if change.Fields&deltaInventory != 0 {
+			unit.Inventory = change.Inventory
+		}
+		if change.Fields&deltaEffects != 0 {
+			unit.Boost = change.Boost
+			unit.Shield = change.Shield
+		}
+		if change.Fields&deltaSequence != 0 {
+			unit.Sequence = change.Sequence
+		}
+		if change.Fields&deltaInputTicks != 0 {
+			unit.InputTicks = change.InputTicks
+		}
+		next.Units[change.Id] = unit
+	}
+
+	return next
+}
+
+// keepBaseline stores a decoded snapshot so later deltas can refer to it.
+// The server never goes back to a base older than the last one it used, so
+// anything before oldest is dropped.
+func (world *World) keepBaseline(snapshot *EventSnapshot, oldest uint64) {
+	if world.baselines == nil {
+		world.baselines = map[uint64]*EventSnapshot{}
+	}
+	world.baselines[snapshot.Tick] = snapshot
+	if snapshot.Tick > world.newest {
+		world.newest = snapshot.Tick
+	}
+
+	for tick := range world.baselines {
+		if tick < oldest || tick+maxBaselines*TickRate/SnapshotRate < snapshot.Tick {
+			delete(world.baselines, tick)
+		}
+	}
+}
+
+// Acknowledgement returns the tick of the newest snapshot decoded since the
+// last call, if there is one. Replicas report it to the server, which then
+// sends deltas against it.
+func (world *World) Acknowledgement() (uint64, bool) {
+	if world.newest == world.announced {
+		return 0, false
+	}
+	world.announced = world.newest
+
+	return world.newest, true
+}
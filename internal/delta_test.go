@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+)
+
+func TestDiffPatch(t *testing.T) {
+	base := &EventSnapshot{
+		Tick: 10,
+		Units: map[string]*Unit{
+			"a": {Id: "a", X: 1, Y: 2, Action: UnitActionIdle, Health: 6, MaxHealth: 6, Sequence: 3, InputTicks: 3,
+				Inventory: []*Slot{{Item: "coin", Count: 1}}},
+			"b": {Id: "b", X: 5, Y: 5, Action: UnitActionIdle, Health: 4, MaxHealth: 6, Sequence: 7, InputTicks: 7,
+				Inventory: []*Slot{{Item: "flask_red", Count: 1}}},
+			"c": {Id: "c", X: 9, Y: 9},
+			"e": {Id: "e", X: 3, Y: 3, Skin: "goblin"},
+		},
+		Items: map[string]*Item{
+			"coin": {Id: "coin", Kind: "coin", X: 1, Y: 1},
+			"gone": {Id: "gone", Kind: "flask_red", X: 2, Y: 2},
+		},
+		Objects: map[string]*Object{
+			"chest": {Id: "chest", Kind: ObjectChest, State: ChestClosed, X: 8, Y: 8},
+			"door":  {Id: "door", Kind: ObjectDoor, State: DoorClosed},
+			"spike": {Id: "spike", Kind: ObjectSpikes, State: SpikesDown, Timer: 5},
+		},
+	}
+	next := &EventSnapshot{
+		Tick: 14,
+		Units: map[string]*Unit{
+			"a": {Id: "a", X: 2, Y: 3, Action: UnitActionMove, MoveX: 1, MoveY: 1, Side: 1, Weapon: "weapon_axe",
+				Swing: 2, AimX: 1, Stun: 1, Health: 5, MaxHealth: 6, Respawn: 0, Boost: 3, Shield: 1,
+				Sequence: 5, InputTicks: 4, Inventory: []*Slot{{Item: "coin", Count: 2}}},
+			"b": {Id: "b", X: 6, Y: 5, Action: UnitActionIdle, Health: 0, MaxHealth: 6, Respawn: 40, Sequence: 9,
+				InputTicks: 8, Inventory: nil},
+			"d": {Id: "d", X: 4, Y: 4, Skin: "imp"},
+			"e": {Id: "e", X: 3, Y: 3, Skin: "goblin"},
+		},
+		Items: map[string]*Item{
+			"coin": {Id: "coin", Kind: "coin", X: 1, Y: 1},
+			"new":  {Id: "new", Kind: "coin", X: 6, Y: 6},
+		},
+		Objects: map[string]*Object{
+			"chest":  {Id: "chest", Kind: ObjectChest, State: ChestFull, X: 8, Y: 8, Timer: 12},
+			"door":   {Id: "door", Kind: ObjectDoor, State: DoorClosed},
+			"banner": {Id: "banner", Kind: ObjectDecoration, State: "wall_banner_red"},
+		},
+	}
+	original := proto.Clone(base).(*EventSnapshot)
+
+	delta := Diff(base, next, "a")
+	if delta.Tick != next.Tick || delta.Base != base.Tick {
+		t.Errorf("delta from %d to %d, want from %d to %d", delta.Base, delta.Tick, base.Tick, next.Tick)
+	}
+	for _, change := range delta.Changed {
+		if change.Id == "e" {
+			t.Error("unchanged unit e was sent")
+		}
+		if change.Id == "b" && change.Fields&(deltaRespawn|deltaInventory|deltaSequence|deltaInputTicks) != 0 {
+			t.Errorf("owner-only fields of b were sent to a: %b", change.Fields)
+		}
+	}
+	if len(delta.Objects) != 2 {
+		t.Errorf("%d objects sent, want the changed chest and the new banner", len(delta.Objects))
+	}
+
+	// Deltas go over the wire.
+	data, err := proto.Marshal(delta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &EventDelta{}
+	if err := proto.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	patched := Patch(base, decoded)
+
+	if !proto.Equal(base, original) {
+		t.Error("Patch modified the base")
+	}
+	if patched.Tick != next.Tick {
+		t.Errorf("tick %d, want %d", patched.Tick, next.Tick)
+	}
+
+	// The owner gets its unit exactly; other clients keep what they knew
+	// of the owner-only fields of b.
+	want := proto.Clone(next).(*EventSnapshot)
+	old := base.Units["b"]
+	b := want.Units["b"]
+	b.Respawn, b.Inventory, b.Sequence, b.InputTicks = old.Respawn, old.Inventory, old.Sequence, old.InputTicks
+
+	if len(patched.Units) != len(want.Units) {
+		t.Errorf("%d units, want %d", len(patched.Units), len(want.Units))
+	}
+	for id, unit := range want.Units {
+		if !proto.Equal(patched.Units[id], unit) {
+			t.Errorf("unit %s is %v, want %v", id, patched.Units[id], unit)
+		}
+	}
+	if len(patched.Items) != len(want.Items) {
+		t.Errorf("%d items, want %d", len(patched.Items), len(want.Items))
+	}
+	for id, item := range want.Items {
+		if !proto.Equal(patched.Items[id], item) {
+			t.Errorf("item %s is %v, want %v", id, patched.Items[id], item)
+		}
+	}
+	if len(patched.Objects) != len(want.Objects) {
+		t.Errorf("%d objects, want %d", len(patched.Objects), len(want.Objects))
+	}
+	for id, object := range want.Objects {
+		if !proto.Equal(patched.Objects[id], object) {
+			t.Errorf("object %s is %v, want %v", id, patched.Objects[id], object)
+		}
+	}
+}
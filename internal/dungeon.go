@@ -0,0 +1,451 @@
+package internal
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"math/rand"
+)
+
+// Dungeons are made by splitting the map in two again and again, putting a
+// room in every part that is left and joining the rooms of both halves of
+// every split with a corridor. The same name and seed always give the same
+// level, so clients build the floor the server plays on from the seed.
+
+// DungeonWidth and DungeonHeight are the size of generated levels in tiles.
+const DungeonWidth = 64
+const DungeonHeight = 48
+
+// Rooms are at least minRoom tiles wide and high, in parts of the map at
+// least minLeaf tiles wide and high. Corridors are corridorWidth tiles
+// wide, so big monsters fit through.
+const minRoom = 4
+const minLeaf = 12
+const corridorWidth = 2
+
+// Chances of a wall face getting a banner, goo or a hole, of a room having
+// a fountain, a chest or a row of spikes, and of a floor tile being one of
+// the cracked kinds.
+const (
+	bannerChance   = 0.08
+	gooChance      = 0.05
+	wallHoleChance = 0.04
+	fountainChance = 0.3
+	chestChance    = 0.5
+	spikesChance   = 0.3
+	crackedChance  = 0.12
+)
+
+// spawnsPerRoom is how many spawn points every room gets.
+const spawnsPerRoom = 2
+
+// DungeonSpawnTable populates generated levels. Count is raised to the
+// number of rooms.
+var DungeonSpawnTable = SpawnTable{
+	Count: 6,
+	Entries: []SpawnEntry{
+		{Skin: "goblin", Weight: 4},
+		{Skin: "imp", Weight: 2},
+		{Skin: "skelet", Weight: 3, Patrol: true},
+		{Skin: "orc_warrior", Weight: 2, Patrol: true},
+		{Skin: "chort", Weight: 1},
+		{Skin: "necromancer", Weight: 1},
+		{Skin: "ogre", Weight: 1},
+	},
+}
+
+var bannerColors = []string{"blue", "green", "red", "yellow"}
+
+// dungeon is a level being generated.
+type dungeon struct {
+	rand  *rand.Rand
+	open  [][]bool
+	rooms []image.Rectangle
+	used  map[image.Point]bool
+	level *Level
+}
+
+// leaf is a part of the map: split in two halves or holding a room.
+type leaf struct {
+	area        image.Rectangle
+	left, right *leaf
+	room        image.Rectangle
+}
+
+// Generate makes a dungeon level from a seed. A ladder in the first room
+// leads up to the level named up and one in the room farthest from it down
+// to the level named down, unless the names are empty. The ladders are
+// placed last, so the tiles only depend on the seed.
+func Generate(name string, seed int64, up, down string) *Level {
+	d := &dungeon{
+		rand: rand.New(rand.NewSource(seed)),
+		used: map[image.Point]bool{},
+		level: &Level{
+			Name:   name,
+			Seed:   seed,
+			Solids: map[string]bool{"wall_goo_base": false},
+		},
+	}
+	d.open = make([][]bool, DungeonHeight)
+	for j := range d.open {
+		d.open[j] = make([]bool, DungeonWidth)
+	}
+
+	// Keep a border for the walls around the rooms at the edges.
+	root := &leaf{area: image.Rect(1, 1, DungeonWidth-1, DungeonHeight-1)}
+	d.split(root)
+	d.furnish(root)
+	d.connect(root)
+
+	floor, walls, decorations := d.layer(), d.layer(), d.layer()
+	d.level.Layers = [][][]string{floor, walls, decorations}
+	d.paint(floor, walls, decorations)
+	d.place()
+	d.ladders(up, down)
+
+	return d.level
+}
+
+// OpenLevel returns the level with the given name: generated from the seed
+// or, without one, loaded from asset/levels.
+func OpenLevel(name string, seed int64) (*Level, error) {
+	if seed != 0 {
+		return Generate(name, seed, "", ""), nil
+	}
+	return LoadLevel(name)
+}
+
+func (d *dungeon) layer() [][]string {
+	layer := make([][]string, DungeonHeight)
+	for j := range layer {
+		layer[j] = make([]string, DungeonWidth)
+	}
+	return layer
+}
+
+// split halves the leaf across its longer side until the parts would get
+// too small.
+func (d *dungeon) split(node *leaf) {
+	w, h := node.area.Dx(), node.area.Dy()
+	vertical := d.rand.Intn(2) == 0
+	switch {
+	case w > h*5/4:
+		vertical = true
+	case h > w*5/4:
+		vertical = false
+	}
+
+	size := h
+	if vertical {
+		size = w
+	}
+	if size < 2*minLeaf {
+		return
+	}
+
+	at := minLeaf + d.rand.Intn(size-2*minLeaf+1)
+	a, b := node.area, node.area
+	if vertical {
+		a.Max.X, b.Min.X = a.Min.X+at, a.Min.X+at
+	} else {
+		a.Max.Y, b.Min.Y = a.Min.Y+at, a.Min.Y+at
+	}
+	node.left, node.right = &leaf{area: a}, &leaf{area: b}
+	d.split(node.left)
+	d.split(node.right)
+}
+
+// furnish digs a room of random size in every leaf. Rooms keep away from
+// the edges of their leaf: two tiles above for the face and top of the
+// wall, one on the other sides.
+func (d *dungeon) furnish(node *leaf) {
+	if node.left != nil {
+		d.furnish(node.left)
+		d.furnish(node.right)
+		return
+	}
+
+	area := image.Rect(node.area.Min.X+1, node.area.Min.Y+2, node.area.Max.X-1, node.area.Max.Y-1)
+	w := minRoom + d.rand.Intn(area.Dx()-minRoom+1)
+	h := minRoom + d.rand.Intn(area.Dy()-minRoom+1)
+	x := area.Min.X + d.rand.Intn(area.Dx()-w+1)
+	y := area.Min.Y + d.rand.Intn(area.Dy()-h+1)
+
+	node.room = image.Rect(x, y, x+w, y+h)
+	d.rooms = append(d.rooms, node.room)
+	d.dig(node.room)
+}
+
+// connect joins a room of each half of every split with a corridor going
+// across and then along.
+func (d *dungeon) connect(node *leaf) {
+	if node.left == nil {
+		return
+	}
+	d.connect(node.left)
+	d.connect(node.right)
+
+	a, b := d.pick(node.left), d.pick(node.right)
+	ax, ay := d.inside(a)
+	bx, by := d.inside(b)
+	if d.rand.Intn(2) == 0 {
+		d.dig(image.Rect(min(ax, bx), ay, max(ax, bx)+corridorWidth, ay+corridorWidth))
+		d.dig(image.Rect(bx, min(ay, by), bx+corridorWidth, max(ay, by)+corridorWidth))
+	} else {
+		d.dig(image.Rect(ax, min(ay, by), ax+corridorWidth, max(ay, by)+corridorWidth))
+		d.dig(image.Rect(min(ax, bx), by, max(ax, bx)+corridorWidth, by+corridorWidth))
+	}
+}
+
+// pick returns the room of a random leaf under the node.
+func (d *dungeon) pick(node *leaf) image.Rectangle {
+	for node.left != nil {
+		if d.rand.Intn(2) == 0 {
+			node = node.left
+		} else {
+			node = node.right
+		}
+	}
+	return node.room
+}
+
+// inside returns a tile of the room a corridor can start from.
+func (d *dungeon) inside(room image.Rectangle) (int, int) {
+	return room.Min.X + d.rand.Intn(room.Dx()-corridorWidth+1), room.Min.Y + d.rand.Intn(room.Dy()-corridorWidth+1)
+}
+
+func (d *dungeon) dig(area image.Rectangle) {
+	for j := area.Min.Y; j < area.Max.Y; j++ {
+		for i := area.Min.X; i < area.Max.X; i++ {
+			d.open[j][i] = true
+		}
+	}
+}
+
+// floor reports whether the tile is dug out. Everything outside the map is
+// rock.
+func (d *dungeon) floor(i, j int) bool {
+	return i >= 0 && j >= 0 && i < DungeonWidth && j < DungeonHeight && d.open[j][i]
+}
+
+// face reports whether the tile is the front of a wall: rock with floor
+// right below it.
+func (d *dungeon) face(i, j int) bool {
+	return !d.floor(i, j) && d.floor(i, j+1)
+}
+
+// wall picks the sprite of a rock tile from the floor around it. Faces
+// look down on the floor, with the top of the wall above them; the other
+// sides of a room are bordered by the sides of the wall.
+func (d *dungeon) wall(i, j int) string {
+	switch {
+	case d.floor(i, j+1):
+		return "wall_mid"
+	case d.face(i, j+1):
+		return "wall_top_mid"
+	case d.floor(i+1, j) || d.floor(i+1, j+1):
+		return "wall_side_mid_left"
+	case d.floor(i-1, j) || d.floor(i-1, j+1):
+		return "wall_side_mid_right"
+	case d.floor(i, j-1):
+		return "wall_top_mid"
+	case d.floor(i+1, j-1):
+		return "wall_side_front_left"
+	case d.floor(i-1, j-1):
+		return "wall_side_front_right"
+	}
+	return ""
+}
+
+// paint fills the layers: floor on the dug out tiles, walls by their
+// neighborhood around them and decorations on the faces of the walls.
+func (d *dungeon) paint(floor, walls, decorations [][]string) {
+	for j := 0; j < DungeonHeight; j++ {
+		for i := 0; i < DungeonWidth; i++ {
+			if d.floor(i, j) {
+				floor[j][i] = "floor_1"
+				if d.rand.Float64() < crackedChance {
+					floor[j][i] = fmt.Sprintf("floor_%d", 2+d.rand.Intn(7))
+				}
+				continue
+			}
+			walls[j][i] = d.wall(i, j)
+		}
+	}
+
+	// The sides of the wall end in a top where they meet the top of the
+	// faces.
+	for j := 0; j < DungeonHeight-1; j++ {
+		for i := 0; i < DungeonWidth; i++ {
+			if walls[j][i] != "" || d.floor(i, j) {
+				continue
+			}
+			switch walls[j+1][i] {
+			case "wall_side_mid_left":
+				walls[j][i] = "wall_side_top_left"
+			case "wall_side_mid_right":
+				walls[j][i] = "wall_side_top_right"
+			}
+		}
+	}
+
+	for j := 0; j < DungeonHeight; j++ {
+		for i := 0; i < DungeonWidth; i++ {
+			// Only faces in the middle of a wall get decorated.
+			if !d.face(i, j) || !d.face(i-1, j) || !d.face(i+1, j) {
+				continue
+			}
+			switch roll := d.rand.Float64(); {
+			case roll < bannerChance:
+				decorations[j][i] = "wall_banner_" + bannerColors[d.rand.Intn(len(bannerColors))]
+			case roll < bannerChance+gooChance:
+				decorations[j][i] = "wall_goo"
+				decorations[j+1][i] = "wall_goo_base"
+				d.used[image.Pt(i, j+1)] = true
+			case roll < bannerChance+gooChance+wallHoleChance:
+				walls[j][i] = fmt.Sprintf("wall_hole_%d", 1+d.rand.Intn(2))
+			}
+		}
+	}
+}
+
+// place puts fountains, chests and spikes into the rooms and marks where
+// units may spawn. The first room is left without chests and spikes.
+func (d *dungeon) place() {
+	for n, room := range d.rooms {
+		if d.rand.Float64() < fountainChance {
+			d.fountain(n, room)
+		}
+		if n > 0 && d.rand.Float64() < chestChance {
+			if tile, ok := d.free(room); ok {
+				d.object(fmt.Sprintf("chest_%d", n), ObjectChest, tile, ChestClosed)
+			}
+		}
+		if n > 0 && d.rand.Float64() < spikesChance {
+			d.spikes(n, room)
+		}
+		for k := 0; k < spawnsPerRoom; k++ {
+			if tile, ok := d.free(room); ok {
+				d.used[tile] = true
+				d.level.Spawns = append(d.level.Spawns, image.Pt(tile.X*TileSize+TileSize/2, tile.Y*TileSize+TileSize/2))
+			}
+		}
+	}
+
+	d.level.SpawnTable = DungeonSpawnTable
+	d.level.SpawnTable.Count = max(d.level.SpawnTable.Count, len(d.rooms))
+}
+
+// ladders puts the ladder up into the first room and the ladder down into
+// the room farthest from it.
+func (d *dungeon) ladders(up, down string) {
+	first := d.rooms[0]
+	if tile, ok := d.clearing(first); ok && up != "" {
+		ladder := d.object("ladder_up", ObjectLadder, tile, "")
+		ladder.Target = up
+	}
+
+	last := first
+	for _, room := range d.rooms {
+		if distance(room, first) > distance(last, first) {
+			last = room
+		}
+	}
+	if tile, ok := d.clearing(last); ok && down != "" {
+		ladder := d.object("ladder_down", ObjectLadder, tile, "")
+		ladder.Target = down
+	}
+}
+
+// distance is how far apart the middles of two rooms are, in tiles.
+func distance(a, b image.Rectangle) float64 {
+	d := a.Min.Add(a.Max).Sub(b.Min.Add(b.Max)).Div(2)
+	return math.Hypot(float64(d.X), float64(d.Y))
+}
+
+// fountain builds a fountain into the wall above the room: its top on the
+// top of the wall, the spout on the face and the basin on the floor.
+func (d *dungeon) fountain(n int, room image.Rectangle) {
+	i := room.Min.X + d.rand.Intn(room.Dx())
+	j := room.Min.Y
+	if !d.face(i, j-1) || d.floor(i, j-2) || d.used[image.Pt(i, j)] {
+		return
+	}
+
+	color := "blue"
+	if d.rand.Intn(2) == 0 {
+		color = "red"
+	}
+	d.object(fmt.Sprintf("fountain_top_%d", n), ObjectDecoration, image.Pt(i, j-2), "wall_fountain_top")
+	d.object(fmt.Sprintf("fountain_mid_%d", n), ObjectDecoration, image.Pt(i, j-1), "wall_fountain_mid_"+color)
+	d.object(fmt.Sprintf("fountain_basin_%d", n), ObjectDecoration, image.Pt(i, j), "wall_fountain_basin_"+color)
+	d.level.Layers[2][j-1][i] = ""
+}
+
+// spikes lays a row of spikes across the room, rising one after another.
+func (d *dungeon) spikes(n int, room image.Rectangle) {
+	j := room.Min.Y + d.rand.Intn(room.Dy())
+	for k, i := 0, room.Min.X+d.rand.Intn(room.Dx()); k < 3 && i < room.Max.X; k, i = k+1, i+1 {
+		tile := image.Pt(i, j)
+		if d.used[tile] {
+			break
+		}
+		spikes := d.object(fmt.Sprintf("spikes_%d_%d", n, k), ObjectSpikes, tile, SpikesDown)
+		spikes.Timer = uint32(30 + 15*k)
+	}
+}
+
+// free returns a random tile of the room nothing was put on yet.
+func (d *dungeon) free(room image.Rectangle) (image.Point, bool) {
+	for tries := 0; tries < 8; tries++ {
+		tile := image.Pt(room.Min.X+d.rand.Intn(room.Dx()), room.Min.Y+d.rand.Intn(room.Dy()))
+		if !d.used[tile] {
+			return tile, true
+		}
+	}
+	return image.Point{}, false
+}
+
+// clearing returns a random free tile of the room with floor all around
+// it, so units of any size arriving by a ladder there fit, or any free tile
+// if there is none.
+func (d *dungeon) clearing(room image.Rectangle) (image.Point, bool) {
+	var tiles []image.Point
+	inner := room.Inset(1)
+	for j := inner.Min.Y; j < inner.Max.Y; j++ {
+		for i := inner.Min.X; i < inner.Max.X; i++ {
+			if !d.used[image.Pt(i, j)] && d.surrounded(i, j) {
+				tiles = append(tiles, image.Pt(i, j))
+			}
+		}
+	}
+	if len(tiles) == 0 {
+		return d.free(room)
+	}
+	return tiles[d.rand.Intn(len(tiles))], true
+}
+
+// surrounded reports whether the eight tiles around the tile are floor.
+func (d *dungeon) surrounded(i, j int) bool {
+	for _, step := range neighbors {
+		if !d.floor(i+step.X, j+step.Y) {
+			return false
+		}
+	}
+	return true
+}
+
+// object adds an object of a tile to the level on the tile.
+func (d *dungeon) object(id, kind string, tile image.Point, state string) *Object {
+	d.used[tile] = true
+	object := &Object{
+		Id:    id,
+		Kind:  kind,
+		X:     (float64(tile.X) + 0.5) * TileSize,
+		Y:     (float64(tile.Y) + 0.5) * TileSize,
+		State: state,
+	}
+	d.level.Objects = append(d.level.Objects, object)
+
+	return object
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"image"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// TestGenerateDeterministic checks that a floor comes out the same for the
+// same name and seed, and that the ladders do not change its tiles, since
+// clients rebuild it from the seed alone.
+func TestGenerateDeterministic(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		a := Generate("dungeon_2", seed, "dungeon_1", "dungeon_3")
+		b := Generate("dungeon_2", seed, "dungeon_1", "dungeon_3")
+		if !reflect.DeepEqual(a.Layers, b.Layers) {
+			t.Fatalf("seed %d: layers differ between runs", seed)
+		}
+		if !reflect.DeepEqual(a.Spawns, b.Spawns) || len(a.Objects) != len(b.Objects) {
+			t.Fatalf("seed %d: spawns or objects differ between runs", seed)
+		}
+		for i := range a.Objects {
+			if !proto.Equal(a.Objects[i], b.Objects[i]) {
+				t.Fatalf("seed %d: object %v, then %v", seed, a.Objects[i], b.Objects[i])
+			}
+		}
+
+		// Clients open levels without knowing where the ladders go.
+		client, err := OpenLevel("dungeon_2", seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(client.Layers, a.Layers) {
+			t.Fatalf("seed %d: layers of the client differ from the server", seed)
+		}
+	}
+
+	if reflect.DeepEqual(Generate("dungeon_1", 1, "", "").Layers, Generate("dungeon_1", 2, "", "").Layers) {
+		t.Error("different seeds give the same layers")
+	}
+}
+
+// TestGenerateReachable checks that both ladders are placed and that they
+// and the spawn points of every room can be reached from the ladder up.
+func TestGenerateReachable(t *testing.T) {
+	for seed := int64(1); seed <= 50; seed++ {
+		level := Generate("dungeon_2", seed, "dungeon_1", "dungeon_3")
+
+		var up, down *Object
+		for _, object := range level.Objects {
+			switch {
+			case object.Kind == ObjectLadder && object.Target == "dungeon_1":
+				up = object
+			case object.Kind == ObjectLadder && object.Target == "dungeon_3":
+				down = object
+			}
+		}
+		if up == nil || down == nil {
+			t.Fatalf("seed %d: ladder up %v, ladder down %v", seed, up, down)
+		}
+
+		from := pixelTile(up.X, up.Y)
+		goals := []image.Point{pixelTile(down.X, down.Y)}
+		for _, spawn := range level.Spawns {
+			goals = append(goals, pixelTile(float64(spawn.X), float64(spawn.Y)))
+		}
+		for _, goal := range goals {
+			finder := NewPathfinder(level.Solid)
+			if _, ok := finder.Find(from, goal); !ok && goal != from {
+				t.Errorf("seed %d: no way from %v to %v", seed, from, goal)
+			}
+		}
+	}
+}
+
+func pixelTile(x, y float64) image.Point {
+	return image.Pt(int(math.Floor(x/TileSize)), int(math.Floor(y/TileSize)))
+}
@@ -55,30 +55,51 @@ func (Direction) EnumDescriptor() ([]byte, []int) {
 type Event_Type int32
 
 const (
-	Event_type_init    Event_Type = 0
-	Event_type_connect Event_Type = 1
-	Event_type_exit    Event_Type = 2
-	Event_type_idle    Event_Type = 3
-	Event_type_move    Event_Type = 4
-	Event_type_empty   Event_Type = 5
+	Event_type_init     Event_Type = 0
+	Event_type_connect  Event_Type = 1
+	Event_type_exit     Event_Type = 2
+	Event_type_idle     Event_Type = 3
+	Event_type_move     Event_Type = 4
+	Event_type_empty    Event_Type = 5
+	Event_type_snapshot Event_Type = 6
+	Event_type_delta    Event_Type = 7
+	Event_type_ack      Event_Type = 8
+	Event_type_attack   Event_Type = 9
+	Event_type_death    Event_Type = 10
+	Event_type_use      Event_Type = 11
+	Event_type_interact Event_Type = 12
 )
 
 var Event_Type_name = map[int32]string{
-	0: "type_init",
-	1: "type_connect",
-	2: "type_exit",
-	3: "type_idle",
-	4: "type_move",
-	5: "type_empty",
+	0:  "type_init",
+	1:  "type_connect",
+	2:  "type_exit",
+	3:  "type_idle",
+	4:  "type_move",
+	5:  "type_empty",
+	6:  "type_snapshot",
+	7:  "type_delta",
+	8:  "type_ack",
+	9:  "type_attack",
+	10: "type_death",
+	11: "type_use",
+	12: "type_interact",
 }
 
 var Event_Type_value = map[string]int32{
-	"type_init":    0,
-	"type_connect": 1,
-	"type_exit":    2,
-	"type_idle":    3,
-	"type_move":    4,
-	"type_empty":   5,
+	"type_init":     0,
+	"type_connect":  1,
+	"type_exit":     2,
+	"type_idle":     3,
+	"type_move":     4,
+	"type_empty":    5,
+	"type_snapshot": 6,
+	"type_delta":    7,
+	"type_ack":      8,
+	"type_attack":   9,
+	"type_death":    10,
+	"type_use":      11,
+	"type_interact": 12,
 }
 
 func (x Event_Type) String() string {
@@ -86,7 +107,7 @@ func (x Event_Type) String() string {
 }
 
 func (Event_Type) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_8f22242cb04491f9, []int{1, 0}
+	return fileDescriptor_8f22242cb04491f9, []int{4, 0}
 }
 
 type Unit struct {
@@ -97,8 +118,27 @@ type Unit struct {
 	Skin                 string    `protobuf:"bytes,5,opt,name=skin,proto3" json:"skin,omitempty"`
 	Action               string    `protobuf:"bytes,6,opt,name=action,proto3" json:"action,omitempty"`
 	Speed                float64   `protobuf:"fixed64,7,opt,name=speed,proto3" json:"speed,omitempty"`
-	Direction            Direction `protobuf:"varint,8,opt,name=direction,proto3,enum=tinyrpg.Direction" json:"direction,omitempty"`
 	Side                 Direction `protobuf:"varint,9,opt,name=side,proto3,enum=tinyrpg.Direction" json:"side,omitempty"`
+	Sequence             uint32    `protobuf:"varint,10,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	InputTicks           uint32    `protobuf:"varint,11,opt,name=input_ticks,json=inputTicks,proto3" json:"input_ticks,omitempty"`
+	Radius               float64   `protobuf:"fixed64,12,opt,name=radius,proto3" json:"radius,omitempty"`
+	Mass                 float64   `protobuf:"fixed64,13,opt,name=mass,proto3" json:"mass,omitempty"`
+	MoveX                float64   `protobuf:"fixed64,14,opt,name=move_x,json=moveX,proto3" json:"move_x,omitempty"`
+	MoveY                float64   `protobuf:"fixed64,15,opt,name=move_y,json=moveY,proto3" json:"move_y,omitempty"`
+	Weapon               string    `protobuf:"bytes,16,opt,name=weapon,proto3" json:"weapon,omitempty"`
+	Cooldown             uint32    `protobuf:"varint,17,opt,name=cooldown,proto3" json:"cooldown,omitempty"`
+	Swing                uint32    `protobuf:"varint,18,opt,name=swing,proto3" json:"swing,omitempty"`
+	Stun                 uint32    `protobuf:"varint,19,opt,name=stun,proto3" json:"stun,omitempty"`
+	PushX                float64   `protobuf:"fixed64,20,opt,name=push_x,json=pushX,proto3" json:"push_x,omitempty"`
+	PushY                float64   `protobuf:"fixed64,21,opt,name=push_y,json=pushY,proto3" json:"push_y,omitempty"`
+	AimX                 float64   `protobuf:"fixed64,22,opt,name=aim_x,json=aimX,proto3" json:"aim_x,omitempty"`
+	AimY                 float64   `protobuf:"fixed64,23,opt,name=aim_y,json=aimY,proto3" json:"aim_y,omitempty"`
+	Health               int32     `protobuf:"varint,24,opt,name=health,proto3" json:"health,omitempty"`
+	MaxHealth            int32     `protobuf:"varint,25,opt,name=max_health,json=maxHealth,proto3" json:"max_health,omitempty"`
+	Respawn              uint32    `protobuf:"varint,26,opt,name=respawn,proto3" json:"respawn,omitempty"`
+	Inventory            []*Slot   `protobuf:"bytes,27,rep,name=inventory,proto3" json:"inventory,omitempty"`
+	Boost                uint32    `protobuf:"varint,28,opt,name=boost,proto3" json:"boost,omitempty"`
+	Shield               uint32    `protobuf:"varint,29,opt,name=shield,proto3" json:"shield,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
 	XXX_unrecognized     []byte    `json:"-"`
 	XXX_sizecache        int32     `json:"-"`
@@ -178,368 +218,1454 @@ func (m *Unit) GetSpeed() float64 {
 	return 0
 }
 
-func (m *Unit) GetDirection() Direction {
+func (m *Unit) GetSide() Direction {
+	if m != nil {
+		return m.Side
+	}
+	return Direction_left
+}
+
+func (m *Unit) GetSequence() uint32 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *Unit) GetInputTicks() uint32 {
+	if m != nil {
+		return m.InputTicks
+	}
+	return 0
+}
+
+func (m *Unit) GetRadius() float64 {
+	if m != nil {
+		return m.Radius
+	}
+	return 0
+}
+
+func (m *Unit) GetMass() float64 {
+	if m != nil {
+		return m.Mass
+	}
+	return 0
+}
+
+func (m *Unit) GetMoveX() float64 {
+	if m != nil {
+		return m.MoveX
+	}
+	return 0
+}
+
+func (m *Unit) GetMoveY() float64 {
+	if m != nil {
+		return m.MoveY
+	}
+	return 0
+}
+
+func (m *Unit) GetWeapon() string {
+	if m != nil {
+		return m.Weapon
+	}
+	return ""
+}
+
+func (m *Unit) GetCooldown() uint32 {
+	if m != nil {
+		return m.Cooldown
+	}
+	return 0
+}
+
+func (m *Unit) GetSwing() uint32 {
+	if m != nil {
+		return m.Swing
+	}
+	return 0
+}
+
+func (m *Unit) GetStun() uint32 {
+	if m != nil {
+		return m.Stun
+	}
+	return 0
+}
+
+func (m *Unit) GetPushX() float64 {
+	if m != nil {
+		return m.PushX
+	}
+	return 0
+}
+
+func (m *Unit) GetPushY() float64 {
+	if m != nil {
+		return m.PushY
+	}
+	return 0
+}
+
+func (m *Unit) GetAimX() float64 {
+	if m != nil {
+		return m.AimX
+	}
+	return 0
+}
+
+func (m *Unit) GetAimY() float64 {
+	if m != nil {
+		return m.AimY
+	}
+	return 0
+}
+
+func (m *Unit) GetHealth() int32 {
+	if m != nil {
+		return m.Health
+	}
+	return 0
+}
+
+func (m *Unit) GetMaxHealth() int32 {
+	if m != nil {
+		return m.MaxHealth
+	}
+	return 0
+}
+
+func (m *Unit) GetRespawn() uint32 {
+	if m != nil {
+		return m.Respawn
+	}
+	return 0
+}
+
+func (m *Unit) GetInventory() []*Slot {
+	if m != nil {
+		return m.Inventory
+	}
+	return nil
+}
+
+func (m *Unit) GetBoost() uint32 {
+	if m != nil {
+		return m.Boost
+	}
+	return 0
+}
+
+func (m *Unit) GetShield() uint32 {
+	if m != nil {
+		return m.Shield
+	}
+	return 0
+}
+
+type Slot struct {
+	Item                 string   `protobuf:"bytes,1,opt,name=item,proto3" json:"item,omitempty"`
+	Count                int32    `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Slot) Reset()         { *m = Slot{} }
+func (m *Slot) String() string { return proto.CompactTextString(m) }
+func (*Slot) ProtoMessage()    {}
+func (*Slot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{1}
+}
+
+func (m *Slot) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Slot.Unmarshal(m, b)
+}
+func (m *Slot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Slot.Marshal(b, m, deterministic)
+}
+func (m *Slot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Slot.Merge(m, src)
+}
+func (m *Slot) XXX_Size() int {
+	return xxx_messageInfo_Slot.Size(m)
+}
+func (m *Slot) XXX_DiscardUnknown() {
+	xxx_messageInfo_Slot.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Slot proto.InternalMessageInfo
+
+func (m *Slot) GetItem() string {
+	if m != nil {
+		return m.Item
+	}
+	return ""
+}
+
+func (m *Slot) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type Item struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Kind                 string   `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	X                    float64  `protobuf:"fixed64,3,opt,name=x,proto3" json:"x,omitempty"`
+	Y                    float64  `protobuf:"fixed64,4,opt,name=y,proto3" json:"y,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Item) Reset()         { *m = Item{} }
+func (m *Item) String() string { return proto.CompactTextString(m) }
+func (*Item) ProtoMessage()    {}
+func (*Item) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{2}
+}
+
+func (m *Item) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Item.Unmarshal(m, b)
+}
+func (m *Item) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Item.Marshal(b, m, deterministic)
+}
+func (m *Item) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Item.Merge(m, src)
+}
+func (m *Item) XXX_Size() int {
+	return xxx_messageInfo_Item.Size(m)
+}
+func (m *Item) XXX_DiscardUnknown() {
+	xxx_messageInfo_Item.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Item proto.InternalMessageInfo
+
+func (m *Item) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Item) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *Item) GetX() float64 {
+	if m != nil {
+		return m.X
+	}
+	return 0
+}
+
+func (m *Item) GetY() float64 {
+	if m != nil {
+		return m.Y
+	}
+	return 0
+}
+
+type Object struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Kind                 string   `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	X                    float64  `protobuf:"fixed64,3,opt,name=x,proto3" json:"x,omitempty"`
+	Y                    float64  `protobuf:"fixed64,4,opt,name=y,proto3" json:"y,omitempty"`
+	State                string   `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`
+	Timer                uint32   `protobuf:"varint,6,opt,name=timer,proto3" json:"timer,omitempty"`
+	Width                float64  `protobuf:"fixed64,7,opt,name=width,proto3" json:"width,omitempty"`
+	Height               float64  `protobuf:"fixed64,8,opt,name=height,proto3" json:"height,omitempty"`
+	Target               string   `protobuf:"bytes,9,opt,name=target,proto3" json:"target,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Object) Reset()         { *m = Object{} }
+func (m *Object) String() string { return proto.CompactTextString(m) }
+func (*Object) ProtoMessage()    {}
+func (*Object) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{3}
+}
+
+func (m *Object) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Object.Unmarshal(m, b)
+}
+func (m *Object) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Object.Marshal(b, m, deterministic)
+}
+func (m *Object) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Object.Merge(m, src)
+}
+func (m *Object) XXX_Size() int {
+	return xxx_messageInfo_Object.Size(m)
+}
+func (m *Object) XXX_DiscardUnknown() {
+	xxx_messageInfo_Object.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Object proto.InternalMessageInfo
+
+func (m *Object) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Object) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *Object) GetX() float64 {
+	if m != nil {
+		return m.X
+	}
+	return 0
+}
+
+func (m *Object) GetY() float64 {
+	if m != nil {
+		return m.Y
+	}
+	return 0
+}
+
+func (m *Object) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *Object) GetTimer() uint32 {
+	if m != nil {
+		return m.Timer
+	}
+	return 0
+}
+
+func (m *Object) GetWidth() float64 {
+	if m != nil {
+		return m.Width
+	}
+	return 0
+}
+
+func (m *Object) GetHeight() float64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *Object) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+type Event struct {
+	Type Event_Type `protobuf:"varint,1,opt,name=type,proto3,enum=tinyrpg.Event_Type" json:"type,omitempty"`
+	// Types that are valid to be assigned to Data:
+	//	*Event_Init
+	//	*Event_Connect
+	//	*Event_Exit
+	//	*Event_Idle
+	//	*Event_Move
+	//	*Event_Snapshot
+	//	*Event_Delta
+	//	*Event_Ack
+	//	*Event_Attack
+	//	*Event_Death
+	//	*Event_Use
+	//	*Event_Interact
+	Data                 isEvent_Data `protobuf_oneof:"data"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+func (*Event) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{4}
+}
+
+func (m *Event) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Event.Unmarshal(m, b)
+}
+func (m *Event) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Event.Marshal(b, m, deterministic)
+}
+func (m *Event) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Event.Merge(m, src)
+}
+func (m *Event) XXX_Size() int {
+	return xxx_messageInfo_Event.Size(m)
+}
+func (m *Event) XXX_DiscardUnknown() {
+	xxx_messageInfo_Event.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Event proto.InternalMessageInfo
+
+func (m *Event) GetType() Event_Type {
+	if m != nil {
+		return m.Type
+	}
+	return Event_type_init
+}
+
+type isEvent_Data interface {
+	isEvent_Data()
+}
+
+type Event_Init struct {
+	Init *EventInit `protobuf:"bytes,2,opt,name=init,proto3,oneof"`
+}
+
+type Event_Connect struct {
+	Connect *EventConnect `protobuf:"bytes,3,opt,name=connect,proto3,oneof"`
+}
+
+type Event_Exit struct {
+	Exit *EventExit `protobuf:"bytes,4,opt,name=exit,proto3,oneof"`
+}
+
+type Event_Idle struct {
+	Idle *EventIdle `protobuf:"bytes,5,opt,name=idle,proto3,oneof"`
+}
+
+type Event_Move struct {
+	Move *EventMove `protobuf:"bytes,6,opt,name=move,proto3,oneof"`
+}
+
+type Event_Snapshot struct {
+	Snapshot *EventSnapshot `protobuf:"bytes,7,opt,name=snapshot,proto3,oneof"`
+}
+
+type Event_Delta struct {
+	Delta *EventDelta `protobuf:"bytes,8,opt,name=delta,proto3,oneof"`
+}
+
+type Event_Ack struct {
+	Ack *EventAck `protobuf:"bytes,9,opt,name=ack,proto3,oneof"`
+}
+
+type Event_Attack struct {
+	Attack *EventAttack `protobuf:"bytes,10,opt,name=attack,proto3,oneof"`
+}
+
+type Event_Death struct {
+	Death *EventDeath `protobuf:"bytes,11,opt,name=death,proto3,oneof"`
+}
+
+type Event_Use struct {
+	Use *EventUse `protobuf:"bytes,12,opt,name=use,proto3,oneof"`
+}
+
+type Event_Interact struct {
+	Interact *EventInteract `protobuf:"bytes,13,opt,name=interact,proto3,oneof"`
+}
+
+func (*Event_Init) isEvent_Data() {}
+
+func (*Event_Connect) isEvent_Data() {}
+
+func (*Event_Exit) isEvent_Data() {}
+
+func (*Event_Idle) isEvent_Data() {}
+
+func (*Event_Move) isEvent_Data() {}
+
+func (*Event_Snapshot) isEvent_Data() {}
+
+func (*Event_Delta) isEvent_Data() {}
+
+func (*Event_Ack) isEvent_Data() {}
+
+func (*Event_Attack) isEvent_Data() {}
+
+func (*Event_Death) isEvent_Data() {}
+
+func (*Event_Use) isEvent_Data() {}
+
+func (*Event_Interact) isEvent_Data() {}
+
+func (m *Event) GetData() isEvent_Data {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *Event) GetInit() *EventInit {
+	if x, ok := m.GetData().(*Event_Init); ok {
+		return x.Init
+	}
+	return nil
+}
+
+func (m *Event) GetConnect() *EventConnect {
+	if x, ok := m.GetData().(*Event_Connect); ok {
+		return x.Connect
+	}
+	return nil
+}
+
+func (m *Event) GetExit() *EventExit {
+	if x, ok := m.GetData().(*Event_Exit); ok {
+		return x.Exit
+	}
+	return nil
+}
+
+func (m *Event) GetIdle() *EventIdle {
+	if x, ok := m.GetData().(*Event_Idle); ok {
+		return x.Idle
+	}
+	return nil
+}
+
+func (m *Event) GetMove() *EventMove {
+	if x, ok := m.GetData().(*Event_Move); ok {
+		return x.Move
+	}
+	return nil
+}
+
+func (m *Event) GetSnapshot() *EventSnapshot {
+	if x, ok := m.GetData().(*Event_Snapshot); ok {
+		return x.Snapshot
+	}
+	return nil
+}
+
+func (m *Event) GetDelta() *EventDelta {
+	if x, ok := m.GetData().(*Event_Delta); ok {
+		return x.Delta
+	}
+	return nil
+}
+
+func (m *Event) GetAck() *EventAck {
+	if x, ok := m.GetData().(*Event_Ack); ok {
+		return x.Ack
+	}
+	return nil
+}
+
+func (m *Event) GetAttack() *EventAttack {
+	if x, ok := m.GetData().(*Event_Attack); ok {
+		return x.Attack
+	}
+	return nil
+}
+
+func (m *Event) GetDeath() *EventDeath {
+	if x, ok := m.GetData().(*Event_Death); ok {
+		return x.Death
+	}
+	return nil
+}
+
+func (m *Event) GetUse() *EventUse {
+	if x, ok := m.GetData().(*Event_Use); ok {
+		return x.Use
+	}
+	return nil
+}
+
+func (m *Event) GetInteract() *EventInteract {
+	if x, ok := m.GetData().(*Event_Interact); ok {
+		return x.Interact
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Event) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Event_Init)(nil),
+		(*Event_Connect)(nil),
+		(*Event_Exit)(nil),
+		(*Event_Idle)(nil),
+		(*Event_Move)(nil),
+		(*Event_Snapshot)(nil),
+		(*Event_Delta)(nil),
+		(*Event_Ack)(nil),
+		(*Event_Attack)(nil),
+		(*Event_Death)(nil),
+		(*Event_Use)(nil),
+		(*Event_Interact)(nil),
+	}
+}
+
+type EventInit struct {
+	PlayerId             string           `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Units                map[string]*Unit `protobuf:"bytes,2,rep,name=units,proto3" json:"units,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Level                string           `protobuf:"bytes,3,opt,name=level,proto3" json:"level,omitempty"`
+	Seed                 int64            `protobuf:"varint,4,opt,name=seed,proto3" json:"seed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *EventInit) Reset()         { *m = EventInit{} }
+func (m *EventInit) String() string { return proto.CompactTextString(m) }
+func (*EventInit) ProtoMessage()    {}
+func (*EventInit) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{5}
+}
+
+func (m *EventInit) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventInit.Unmarshal(m, b)
+}
+func (m *EventInit) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventInit.Marshal(b, m, deterministic)
+}
+func (m *EventInit) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventInit.Merge(m, src)
+}
+func (m *EventInit) XXX_Size() int {
+	return xxx_messageInfo_EventInit.Size(m)
+}
+func (m *EventInit) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventInit.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EventInit proto.InternalMessageInfo
+
+func (m *EventInit) GetPlayerId() string {
+	if m != nil {
+		return m.PlayerId
+	}
+	return ""
+}
+
+func (m *EventInit) GetUnits() map[string]*Unit {
+	if m != nil {
+		return m.Units
+	}
+	return nil
+}
+
+func (m *EventInit) GetLevel() string {
+	if m != nil {
+		return m.Level
+	}
+	return ""
+}
+
+func (m *EventInit) GetSeed() int64 {
+	if m != nil {
+		return m.Seed
+	}
+	return 0
+}
+
+type EventConnect struct {
+	Unit                 *Unit    `protobuf:"bytes,1,opt,name=unit,proto3" json:"unit,omitempty"`
+	From                 string   `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EventConnect) Reset()         { *m = EventConnect{} }
+func (m *EventConnect) String() string { return proto.CompactTextString(m) }
+func (*EventConnect) ProtoMessage()    {}
+func (*EventConnect) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{6}
+}
+
+func (m *EventConnect) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventConnect.Unmarshal(m, b)
+}
+func (m *EventConnect) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventConnect.Marshal(b, m, deterministic)
+}
+func (m *EventConnect) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventConnect.Merge(m, src)
+}
+func (m *EventConnect) XXX_Size() int {
+	return xxx_messageInfo_EventConnect.Size(m)
+}
+func (m *EventConnect) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventConnect.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EventConnect proto.InternalMessageInfo
+
+func (m *EventConnect) GetUnit() *Unit {
+	if m != nil {
+		return m.Unit
+	}
+	return nil
+}
+
+func (m *EventConnect) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+type EventExit struct {
+	PlayerId             string   `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EventExit) Reset()         { *m = EventExit{} }
+func (m *EventExit) String() string { return proto.CompactTextString(m) }
+func (*EventExit) ProtoMessage()    {}
+func (*EventExit) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{7}
+}
+
+func (m *EventExit) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventExit.Unmarshal(m, b)
+}
+func (m *EventExit) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventExit.Marshal(b, m, deterministic)
+}
+func (m *EventExit) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventExit.Merge(m, src)
+}
+func (m *EventExit) XXX_Size() int {
+	return xxx_messageInfo_EventExit.Size(m)
+}
+func (m *EventExit) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventExit.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EventExit proto.InternalMessageInfo
+
+func (m *EventExit) GetPlayerId() string {
+	if m != nil {
+		return m.PlayerId
+	}
+	return ""
+}
+
+type EventIdle struct {
+	PlayerId             string   `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Sequence             uint32   `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EventIdle) Reset()         { *m = EventIdle{} }
+func (m *EventIdle) String() string { return proto.CompactTextString(m) }
+func (*EventIdle) ProtoMessage()    {}
+func (*EventIdle) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{8}
+}
+
+func (m *EventIdle) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventIdle.Unmarshal(m, b)
+}
+func (m *EventIdle) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventIdle.Marshal(b, m, deterministic)
+}
+func (m *EventIdle) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventIdle.Merge(m, src)
+}
+func (m *EventIdle) XXX_Size() int {
+	return xxx_messageInfo_EventIdle.Size(m)
+}
+func (m *EventIdle) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventIdle.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EventIdle proto.InternalMessageInfo
+
+func (m *EventIdle) GetPlayerId() string {
+	if m != nil {
+		return m.PlayerId
+	}
+	return ""
+}
+
+func (m *EventIdle) GetSequence() uint32 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+type EventMove struct {
+	PlayerId             string   `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Sequence             uint32   `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	X                    float64  `protobuf:"fixed64,4,opt,name=x,proto3" json:"x,omitempty"`
+	Y                    float64  `protobuf:"fixed64,5,opt,name=y,proto3" json:"y,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EventMove) Reset()         { *m = EventMove{} }
+func (m *EventMove) String() string { return proto.CompactTextString(m) }
+func (*EventMove) ProtoMessage()    {}
+func (*EventMove) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{9}
+}
+
+func (m *EventMove) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventMove.Unmarshal(m, b)
+}
+func (m *EventMove) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventMove.Marshal(b, m, deterministic)
+}
+func (m *EventMove) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventMove.Merge(m, src)
+}
+func (m *EventMove) XXX_Size() int {
+	return xxx_messageInfo_EventMove.Size(m)
+}
+func (m *EventMove) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventMove.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EventMove proto.InternalMessageInfo
+
+func (m *EventMove) GetPlayerId() string {
+	if m != nil {
+		return m.PlayerId
+	}
+	return ""
+}
+
+func (m *EventMove) GetSequence() uint32 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *EventMove) GetX() float64 {
+	if m != nil {
+		return m.X
+	}
+	return 0
+}
+
+func (m *EventMove) GetY() float64 {
+	if m != nil {
+		return m.Y
+	}
+	return 0
+}
+
+type EventSnapshot struct {
+	Tick                 uint64             `protobuf:"varint,1,opt,name=tick,proto3" json:"tick,omitempty"`
+	Units                map[string]*Unit   `protobuf:"bytes,2,rep,name=units,proto3" json:"units,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Items                map[string]*Item   `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Objects              map[string]*Object `protobuf:"bytes,4,rep,name=objects,proto3" json:"objects,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *EventSnapshot) Reset()         { *m = EventSnapshot{} }
+func (m *EventSnapshot) String() string { return proto.CompactTextString(m) }
+func (*EventSnapshot) ProtoMessage()    {}
+func (*EventSnapshot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{10}
+}
+
+func (m *EventSnapshot) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventSnapshot.Unmarshal(m, b)
+}
+func (m *EventSnapshot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventSnapshot.Marshal(b, m, deterministic)
+}
+func (m *EventSnapshot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventSnapshot.Merge(m, src)
+}
+func (m *EventSnapshot) XXX_Size() int {
+	return xxx_messageInfo_EventSnapshot.Size(m)
+}
+func (m *EventSnapshot) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventSnapshot.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EventSnapshot proto.InternalMessageInfo
+
+func (m *EventSnapshot) GetTick() uint64 {
+	if m != nil {
+		return m.Tick
+	}
+	return 0
+}
+
+func (m *EventSnapshot) GetUnits() map[string]*Unit {
+	if m != nil {
+		return m.Units
+	}
+	return nil
+}
+
+func (m *EventSnapshot) GetItems() map[string]*Item {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+func (m *EventSnapshot) GetObjects() map[string]*Object {
+	if m != nil {
+		return m.Objects
+	}
+	return nil
+}
+
+type UnitDelta struct {
+	Id                   string    `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Fields               uint32    `protobuf:"varint,2,opt,name=fields,proto3" json:"fields,omitempty"`
+	X                    float64   `protobuf:"fixed64,3,opt,name=x,proto3" json:"x,omitempty"`
+	Y                    float64   `protobuf:"fixed64,4,opt,name=y,proto3" json:"y,omitempty"`
+	Action               string    `protobuf:"bytes,5,opt,name=action,proto3" json:"action,omitempty"`
+	Side                 Direction `protobuf:"varint,7,opt,name=side,proto3,enum=tinyrpg.Direction" json:"side,omitempty"`
+	Sequence             uint32    `protobuf:"varint,8,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	InputTicks           uint32    `protobuf:"varint,9,opt,name=input_ticks,json=inputTicks,proto3" json:"input_ticks,omitempty"`
+	MoveX                float64   `protobuf:"fixed64,10,opt,name=move_x,json=moveX,proto3" json:"move_x,omitempty"`
+	MoveY                float64   `protobuf:"fixed64,11,opt,name=move_y,json=moveY,proto3" json:"move_y,omitempty"`
+	Weapon               string    `protobuf:"bytes,12,opt,name=weapon,proto3" json:"weapon,omitempty"`
+	Swing                uint32    `protobuf:"varint,13,opt,name=swing,proto3" json:"swing,omitempty"`
+	AimX                 float64   `protobuf:"fixed64,14,opt,name=aim_x,json=aimX,proto3" json:"aim_x,omitempty"`
+	AimY                 float64   `protobuf:"fixed64,15,opt,name=aim_y,json=aimY,proto3" json:"aim_y,omitempty"`
+	Stun                 uint32    `protobuf:"varint,16,opt,name=stun,proto3" json:"stun,omitempty"`
+	Health               int32     `protobuf:"varint,17,opt,name=health,proto3" json:"health,omitempty"`
+	MaxHealth            int32     `protobuf:"varint,18,opt,name=max_health,json=maxHealth,proto3" json:"max_health,omitempty"`
+	Respawn              uint32    `protobuf:"varint,19,opt,name=respawn,proto3" json:"respawn,omitempty"`
+	Inventory            []*Slot   `protobuf:"bytes,20,rep,name=inventory,proto3" json:"inventory,omitempty"`
+	Boost                uint32    `protobuf:"varint,21,opt,name=boost,proto3" json:"boost,omitempty"`
+	Shield               uint32    `protobuf:"varint,22,opt,name=shield,proto3" json:"shield,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *UnitDelta) Reset()         { *m = UnitDelta{} }
+func (m *UnitDelta) String() string { return proto.CompactTextString(m) }
+func (*UnitDelta) ProtoMessage()    {}
+func (*UnitDelta) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{11}
+}
+
+func (m *UnitDelta) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnitDelta.Unmarshal(m, b)
+}
+func (m *UnitDelta) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnitDelta.Marshal(b, m, deterministic)
+}
+func (m *UnitDelta) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnitDelta.Merge(m, src)
+}
+func (m *UnitDelta) XXX_Size() int {
+	return xxx_messageInfo_UnitDelta.Size(m)
+}
+func (m *UnitDelta) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnitDelta.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UnitDelta proto.InternalMessageInfo
+
+func (m *UnitDelta) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *UnitDelta) GetFields() uint32 {
+	if m != nil {
+		return m.Fields
+	}
+	return 0
+}
+
+func (m *UnitDelta) GetX() float64 {
+	if m != nil {
+		return m.X
+	}
+	return 0
+}
+
+func (m *UnitDelta) GetY() float64 {
+	if m != nil {
+		return m.Y
+	}
+	return 0
+}
+
+func (m *UnitDelta) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *UnitDelta) GetSide() Direction {
+	if m != nil {
+		return m.Side
+	}
+	return Direction_left
+}
+
+func (m *UnitDelta) GetSequence() uint32 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *UnitDelta) GetInputTicks() uint32 {
+	if m != nil {
+		return m.InputTicks
+	}
+	return 0
+}
+
+func (m *UnitDelta) GetMoveX() float64 {
+	if m != nil {
+		return m.MoveX
+	}
+	return 0
+}
+
+func (m *UnitDelta) GetMoveY() float64 {
+	if m != nil {
+		return m.MoveY
+	}
+	return 0
+}
+
+func (m *UnitDelta) GetWeapon() string {
+	if m != nil {
+		return m.Weapon
+	}
+	return ""
+}
+
+func (m *UnitDelta) GetSwing() uint32 {
+	if m != nil {
+		return m.Swing
+	}
+	return 0
+}
+
+func (m *UnitDelta) GetAimX() float64 {
+	if m != nil {
+		return m.AimX
+	}
+	return 0
+}
+
+func (m *UnitDelta) GetAimY() float64 {
+	if m != nil {
+		return m.AimY
+	}
+	return 0
+}
+
+func (m *UnitDelta) GetStun() uint32 {
 	if m != nil {
-		return m.Direction
+		return m.Stun
 	}
-	return Direction_left
+	return 0
 }
 
-func (m *Unit) GetSide() Direction {
+func (m *UnitDelta) GetHealth() int32 {
 	if m != nil {
-		return m.Side
+		return m.Health
 	}
-	return Direction_left
+	return 0
 }
 
-type Event struct {
-	Type Event_Type `protobuf:"varint,1,opt,name=type,proto3,enum=tinyrpg.Event_Type" json:"type,omitempty"`
-	// Types that are valid to be assigned to Data:
-	//	*Event_Init
-	//	*Event_Connect
-	//	*Event_Exit
-	//	*Event_Idle
-	//	*Event_Move
-	Data                 isEvent_Data `protobuf_oneof:"data"`
-	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
-	XXX_unrecognized     []byte       `json:"-"`
-	XXX_sizecache        int32        `json:"-"`
+func (m *UnitDelta) GetMaxHealth() int32 {
+	if m != nil {
+		return m.MaxHealth
+	}
+	return 0
 }
 
-func (m *Event) Reset()         { *m = Event{} }
-func (m *Event) String() string { return proto.CompactTextString(m) }
-func (*Event) ProtoMessage()    {}
-func (*Event) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8f22242cb04491f9, []int{1}
+func (m *UnitDelta) GetRespawn() uint32 {
+	if m != nil {
+		return m.Respawn
+	}
+	return 0
 }
 
-func (m *Event) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Event.Unmarshal(m, b)
-}
-func (m *Event) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Event.Marshal(b, m, deterministic)
-}
-func (m *Event) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Event.Merge(m, src)
-}
-func (m *Event) XXX_Size() int {
-	return xxx_messageInfo_Event.Size(m)
-}
-func (m *Event) XXX_DiscardUnknown() {
-	xxx_messageInfo_Event.DiscardUnknown(m)
+func (m *UnitDelta) GetInventory() []*Slot {
+	if m != nil {
+		return m.Inventory
+	}
+	return nil
 }
 
-var xxx_messageInfo_Event proto.InternalMessageInfo
-
-func (m *Event) GetType() Event_Type {
+func (m *UnitDelta) GetBoost() uint32 {
 	if m != nil {
-		return m.Type
+		return m.Boost
 	}
-	return Event_type_init
+	return 0
 }
 
-type isEvent_Data interface {
-	isEvent_Data()
+func (m *UnitDelta) GetShield() uint32 {
+	if m != nil {
+		return m.Shield
+	}
+	return 0
 }
 
-type Event_Init struct {
-	Init *EventInit `protobuf:"bytes,2,opt,name=init,proto3,oneof"`
+type EventDelta struct {
+	Tick                 uint64       `protobuf:"varint,1,opt,name=tick,proto3" json:"tick,omitempty"`
+	Base                 uint64       `protobuf:"varint,2,opt,name=base,proto3" json:"base,omitempty"`
+	Entered              []*Unit      `protobuf:"bytes,3,rep,name=entered,proto3" json:"entered,omitempty"`
+	Changed              []*UnitDelta `protobuf:"bytes,4,rep,name=changed,proto3" json:"changed,omitempty"`
+	Left                 []string     `protobuf:"bytes,5,rep,name=left,proto3" json:"left,omitempty"`
+	ItemsEntered         []*Item      `protobuf:"bytes,6,rep,name=items_entered,json=itemsEntered,proto3" json:"items_entered,omitempty"`
+	ItemsLeft            []string     `protobuf:"bytes,7,rep,name=items_left,json=itemsLeft,proto3" json:"items_left,omitempty"`
+	Objects              []*Object    `protobuf:"bytes,8,rep,name=objects,proto3" json:"objects,omitempty"`
+	ObjectsLeft          []string     `protobuf:"bytes,9,rep,name=objects_left,json=objectsLeft,proto3" json:"objects_left,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
 }
 
-type Event_Connect struct {
-	Connect *EventConnect `protobuf:"bytes,3,opt,name=connect,proto3,oneof"`
+func (m *EventDelta) Reset()         { *m = EventDelta{} }
+func (m *EventDelta) String() string { return proto.CompactTextString(m) }
+func (*EventDelta) ProtoMessage()    {}
+func (*EventDelta) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{12}
 }
 
-type Event_Exit struct {
-	Exit *EventExit `protobuf:"bytes,4,opt,name=exit,proto3,oneof"`
+func (m *EventDelta) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventDelta.Unmarshal(m, b)
 }
-
-type Event_Idle struct {
-	Idle *EventIdle `protobuf:"bytes,5,opt,name=idle,proto3,oneof"`
+func (m *EventDelta) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventDelta.Marshal(b, m, deterministic)
 }
-
-type Event_Move struct {
-	Move *EventMove `protobuf:"bytes,6,opt,name=move,proto3,oneof"`
+func (m *EventDelta) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventDelta.Merge(m, src)
+}
+func (m *EventDelta) XXX_Size() int {
+	return xxx_messageInfo_EventDelta.Size(m)
+}
+func (m *EventDelta) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventDelta.DiscardUnknown(m)
 }
 
-func (*Event_Init) isEvent_Data() {}
-
-func (*Event_Connect) isEvent_Data() {}
-
-func (*Event_Exit) isEvent_Data() {}
+var xxx_messageInfo_EventDelta proto.InternalMessageInfo
 
-func (*Event_Idle) isEvent_Data() {}
+func (m *EventDelta) GetTick() uint64 {
+	if m != nil {
+		return m.Tick
+	}
+	return 0
+}
 
-func (*Event_Move) isEvent_Data() {}
+func (m *EventDelta) GetBase() uint64 {
+	if m != nil {
+		return m.Base
+	}
+	return 0
+}
 
-func (m *Event) GetData() isEvent_Data {
+func (m *EventDelta) GetEntered() []*Unit {
 	if m != nil {
-		return m.Data
+		return m.Entered
 	}
 	return nil
 }
 
-func (m *Event) GetInit() *EventInit {
-	if x, ok := m.GetData().(*Event_Init); ok {
-		return x.Init
+func (m *EventDelta) GetChanged() []*UnitDelta {
+	if m != nil {
+		return m.Changed
 	}
 	return nil
 }
 
-func (m *Event) GetConnect() *EventConnect {
-	if x, ok := m.GetData().(*Event_Connect); ok {
-		return x.Connect
+func (m *EventDelta) GetLeft() []string {
+	if m != nil {
+		return m.Left
 	}
 	return nil
 }
 
-func (m *Event) GetExit() *EventExit {
-	if x, ok := m.GetData().(*Event_Exit); ok {
-		return x.Exit
+func (m *EventDelta) GetItemsEntered() []*Item {
+	if m != nil {
+		return m.ItemsEntered
 	}
 	return nil
 }
 
-func (m *Event) GetIdle() *EventIdle {
-	if x, ok := m.GetData().(*Event_Idle); ok {
-		return x.Idle
+func (m *EventDelta) GetItemsLeft() []string {
+	if m != nil {
+		return m.ItemsLeft
 	}
 	return nil
 }
 
-func (m *Event) GetMove() *EventMove {
-	if x, ok := m.GetData().(*Event_Move); ok {
-		return x.Move
+func (m *EventDelta) GetObjects() []*Object {
+	if m != nil {
+		return m.Objects
 	}
 	return nil
 }
 
-// XXX_OneofWrappers is for the internal use of the proto package.
-func (*Event) XXX_OneofWrappers() []interface{} {
-	return []interface{}{
-		(*Event_Init)(nil),
-		(*Event_Connect)(nil),
-		(*Event_Exit)(nil),
-		(*Event_Idle)(nil),
-		(*Event_Move)(nil),
+func (m *EventDelta) GetObjectsLeft() []string {
+	if m != nil {
+		return m.ObjectsLeft
 	}
+	return nil
 }
 
-type EventInit struct {
-	PlayerId             string           `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
-	Units                map[string]*Unit `protobuf:"bytes,2,rep,name=units,proto3" json:"units,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
-	XXX_unrecognized     []byte           `json:"-"`
-	XXX_sizecache        int32            `json:"-"`
+type EventAck struct {
+	Tick                 uint64   `protobuf:"varint,1,opt,name=tick,proto3" json:"tick,omitempty"`
+	Level                string   `protobuf:"bytes,2,opt,name=level,proto3" json:"level,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *EventInit) Reset()         { *m = EventInit{} }
-func (m *EventInit) String() string { return proto.CompactTextString(m) }
-func (*EventInit) ProtoMessage()    {}
-func (*EventInit) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8f22242cb04491f9, []int{2}
+func (m *EventAck) Reset()         { *m = EventAck{} }
+func (m *EventAck) String() string { return proto.CompactTextString(m) }
+func (*EventAck) ProtoMessage()    {}
+func (*EventAck) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{13}
 }
 
-func (m *EventInit) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_EventInit.Unmarshal(m, b)
+func (m *EventAck) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventAck.Unmarshal(m, b)
 }
-func (m *EventInit) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_EventInit.Marshal(b, m, deterministic)
+func (m *EventAck) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventAck.Marshal(b, m, deterministic)
 }
-func (m *EventInit) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_EventInit.Merge(m, src)
+func (m *EventAck) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventAck.Merge(m, src)
 }
-func (m *EventInit) XXX_Size() int {
-	return xxx_messageInfo_EventInit.Size(m)
+func (m *EventAck) XXX_Size() int {
+	return xxx_messageInfo_EventAck.Size(m)
 }
-func (m *EventInit) XXX_DiscardUnknown() {
-	xxx_messageInfo_EventInit.DiscardUnknown(m)
+func (m *EventAck) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventAck.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_EventInit proto.InternalMessageInfo
+var xxx_messageInfo_EventAck proto.InternalMessageInfo
 
-func (m *EventInit) GetPlayerId() string {
+func (m *EventAck) GetTick() uint64 {
 	if m != nil {
-		return m.PlayerId
+		return m.Tick
 	}
-	return ""
+	return 0
 }
 
-func (m *EventInit) GetUnits() map[string]*Unit {
+func (m *EventAck) GetLevel() string {
 	if m != nil {
-		return m.Units
+		return m.Level
 	}
-	return nil
+	return ""
 }
 
-type EventConnect struct {
-	Unit                 *Unit    `protobuf:"bytes,1,opt,name=unit,proto3" json:"unit,omitempty"`
+type EventAttack struct {
+	PlayerId             string   `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *EventConnect) Reset()         { *m = EventConnect{} }
-func (m *EventConnect) String() string { return proto.CompactTextString(m) }
-func (*EventConnect) ProtoMessage()    {}
-func (*EventConnect) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8f22242cb04491f9, []int{3}
+func (m *EventAttack) Reset()         { *m = EventAttack{} }
+func (m *EventAttack) String() string { return proto.CompactTextString(m) }
+func (*EventAttack) ProtoMessage()    {}
+func (*EventAttack) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{14}
 }
 
-func (m *EventConnect) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_EventConnect.Unmarshal(m, b)
+func (m *EventAttack) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventAttack.Unmarshal(m, b)
 }
-func (m *EventConnect) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_EventConnect.Marshal(b, m, deterministic)
+func (m *EventAttack) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventAttack.Marshal(b, m, deterministic)
 }
-func (m *EventConnect) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_EventConnect.Merge(m, src)
+func (m *EventAttack) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventAttack.Merge(m, src)
 }
-func (m *EventConnect) XXX_Size() int {
-	return xxx_messageInfo_EventConnect.Size(m)
+func (m *EventAttack) XXX_Size() int {
+	return xxx_messageInfo_EventAttack.Size(m)
 }
-func (m *EventConnect) XXX_DiscardUnknown() {
-	xxx_messageInfo_EventConnect.DiscardUnknown(m)
+func (m *EventAttack) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventAttack.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_EventConnect proto.InternalMessageInfo
+var xxx_messageInfo_EventAttack proto.InternalMessageInfo
 
-func (m *EventConnect) GetUnit() *Unit {
+func (m *EventAttack) GetPlayerId() string {
 	if m != nil {
-		return m.Unit
+		return m.PlayerId
 	}
-	return nil
+	return ""
 }
 
-type EventExit struct {
+type EventDeath struct {
 	PlayerId             string   `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	KillerId             string   `protobuf:"bytes,2,opt,name=killer_id,json=killerId,proto3" json:"killer_id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *EventExit) Reset()         { *m = EventExit{} }
-func (m *EventExit) String() string { return proto.CompactTextString(m) }
-func (*EventExit) ProtoMessage()    {}
-func (*EventExit) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8f22242cb04491f9, []int{4}
+func (m *EventDeath) Reset()         { *m = EventDeath{} }
+func (m *EventDeath) String() string { return proto.CompactTextString(m) }
+func (*EventDeath) ProtoMessage()    {}
+func (*EventDeath) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{15}
 }
 
-func (m *EventExit) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_EventExit.Unmarshal(m, b)
+func (m *EventDeath) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventDeath.Unmarshal(m, b)
 }
-func (m *EventExit) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_EventExit.Marshal(b, m, deterministic)
+func (m *EventDeath) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventDeath.Marshal(b, m, deterministic)
 }
-func (m *EventExit) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_EventExit.Merge(m, src)
+func (m *EventDeath) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventDeath.Merge(m, src)
 }
-func (m *EventExit) XXX_Size() int {
-	return xxx_messageInfo_EventExit.Size(m)
+func (m *EventDeath) XXX_Size() int {
+	return xxx_messageInfo_EventDeath.Size(m)
 }
-func (m *EventExit) XXX_DiscardUnknown() {
-	xxx_messageInfo_EventExit.DiscardUnknown(m)
+func (m *EventDeath) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventDeath.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_EventExit proto.InternalMessageInfo
+var xxx_messageInfo_EventDeath proto.InternalMessageInfo
 
-func (m *EventExit) GetPlayerId() string {
+func (m *EventDeath) GetPlayerId() string {
 	if m != nil {
 		return m.PlayerId
 	}
 	return ""
 }
 
-type EventIdle struct {
+func (m *EventDeath) GetKillerId() string {
+	if m != nil {
+		return m.KillerId
+	}
+	return ""
+}
+
+type EventUse struct {
 	PlayerId             string   `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Slot                 int32    `protobuf:"varint,2,opt,name=slot,proto3" json:"slot,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *EventIdle) Reset()         { *m = EventIdle{} }
-func (m *EventIdle) String() string { return proto.CompactTextString(m) }
-func (*EventIdle) ProtoMessage()    {}
-func (*EventIdle) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8f22242cb04491f9, []int{5}
+func (m *EventUse) Reset()         { *m = EventUse{} }
+func (m *EventUse) String() string { return proto.CompactTextString(m) }
+func (*EventUse) ProtoMessage()    {}
+func (*EventUse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{16}
 }
 
-func (m *EventIdle) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_EventIdle.Unmarshal(m, b)
+func (m *EventUse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventUse.Unmarshal(m, b)
 }
-func (m *EventIdle) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_EventIdle.Marshal(b, m, deterministic)
+func (m *EventUse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventUse.Marshal(b, m, deterministic)
 }
-func (m *EventIdle) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_EventIdle.Merge(m, src)
+func (m *EventUse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventUse.Merge(m, src)
 }
-func (m *EventIdle) XXX_Size() int {
-	return xxx_messageInfo_EventIdle.Size(m)
+func (m *EventUse) XXX_Size() int {
+	return xxx_messageInfo_EventUse.Size(m)
 }
-func (m *EventIdle) XXX_DiscardUnknown() {
-	xxx_messageInfo_EventIdle.DiscardUnknown(m)
+func (m *EventUse) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventUse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_EventIdle proto.InternalMessageInfo
+var xxx_messageInfo_EventUse proto.InternalMessageInfo
 
-func (m *EventIdle) GetPlayerId() string {
+func (m *EventUse) GetPlayerId() string {
 	if m != nil {
 		return m.PlayerId
 	}
 	return ""
 }
 
-type EventMove struct {
-	PlayerId             string    `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
-	Direction            Direction `protobuf:"varint,2,opt,name=direction,proto3,enum=tinyrpg.Direction" json:"direction,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
+func (m *EventUse) GetSlot() int32 {
+	if m != nil {
+		return m.Slot
+	}
+	return 0
 }
 
-func (m *EventMove) Reset()         { *m = EventMove{} }
-func (m *EventMove) String() string { return proto.CompactTextString(m) }
-func (*EventMove) ProtoMessage()    {}
-func (*EventMove) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8f22242cb04491f9, []int{6}
+type EventInteract struct {
+	PlayerId             string   `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *EventMove) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_EventMove.Unmarshal(m, b)
+func (m *EventInteract) Reset()         { *m = EventInteract{} }
+func (m *EventInteract) String() string { return proto.CompactTextString(m) }
+func (*EventInteract) ProtoMessage()    {}
+func (*EventInteract) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{17}
 }
-func (m *EventMove) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_EventMove.Marshal(b, m, deterministic)
+
+func (m *EventInteract) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventInteract.Unmarshal(m, b)
 }
-func (m *EventMove) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_EventMove.Merge(m, src)
+func (m *EventInteract) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventInteract.Marshal(b, m, deterministic)
 }
-func (m *EventMove) XXX_Size() int {
-	return xxx_messageInfo_EventMove.Size(m)
+func (m *EventInteract) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventInteract.Merge(m, src)
 }
-func (m *EventMove) XXX_DiscardUnknown() {
-	xxx_messageInfo_EventMove.DiscardUnknown(m)
+func (m *EventInteract) XXX_Size() int {
+	return xxx_messageInfo_EventInteract.Size(m)
+}
+func (m *EventInteract) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventInteract.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_EventMove proto.InternalMessageInfo
+var xxx_messageInfo_EventInteract proto.InternalMessageInfo
 
-func (m *EventMove) GetPlayerId() string {
+func (m *EventInteract) GetPlayerId() string {
 	if m != nil {
 		return m.PlayerId
 	}
 	return ""
 }
 
-func (m *EventMove) GetDirection() Direction {
-	if m != nil {
-		return m.Direction
-	}
-	return Direction_left
-}
-
 func init() {
 	proto.RegisterEnum("tinyrpg.Direction", Direction_name, Direction_value)
 	proto.RegisterEnum("tinyrpg.Event_Type", Event_Type_name, Event_Type_value)
 	proto.RegisterType((*Unit)(nil), "tinyrpg.Unit")
+	proto.RegisterType((*Slot)(nil), "tinyrpg.Slot")
+	proto.RegisterType((*Item)(nil), "tinyrpg.Item")
+	proto.RegisterType((*Object)(nil), "tinyrpg.Object")
 	proto.RegisterType((*Event)(nil), "tinyrpg.Event")
 	proto.RegisterType((*EventInit)(nil), "tinyrpg.EventInit")
 	proto.RegisterMapType((map[string]*Unit)(nil), "tinyrpg.EventInit.UnitsEntry")
@@ -547,44 +1673,115 @@ func init() {
 	proto.RegisterType((*EventExit)(nil), "tinyrpg.EventExit")
 	proto.RegisterType((*EventIdle)(nil), "tinyrpg.EventIdle")
 	proto.RegisterType((*EventMove)(nil), "tinyrpg.EventMove")
+	proto.RegisterType((*EventSnapshot)(nil), "tinyrpg.EventSnapshot")
+	proto.RegisterMapType((map[string]*Item)(nil), "tinyrpg.EventSnapshot.ItemsEntry")
+	proto.RegisterMapType((map[string]*Object)(nil), "tinyrpg.EventSnapshot.ObjectsEntry")
+	proto.RegisterMapType((map[string]*Unit)(nil), "tinyrpg.EventSnapshot.UnitsEntry")
+	proto.RegisterType((*UnitDelta)(nil), "tinyrpg.UnitDelta")
+	proto.RegisterType((*EventDelta)(nil), "tinyrpg.EventDelta")
+	proto.RegisterType((*EventAck)(nil), "tinyrpg.EventAck")
+	proto.RegisterType((*EventAttack)(nil), "tinyrpg.EventAttack")
+	proto.RegisterType((*EventDeath)(nil), "tinyrpg.EventDeath")
+	proto.RegisterType((*EventUse)(nil), "tinyrpg.EventUse")
+	proto.RegisterType((*EventInteract)(nil), "tinyrpg.EventInteract")
 }
 
 func init() { proto.RegisterFile("events.proto", fileDescriptor_8f22242cb04491f9) }
 
 var fileDescriptor_8f22242cb04491f9 = []byte{
-	// 530 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x94, 0xcf, 0x6e, 0xd3, 0x40,
-	0x10, 0xc6, 0xb3, 0x8e, 0x9d, 0xc4, 0x93, 0x34, 0xb2, 0x86, 0x3f, 0xb2, 0x40, 0x48, 0xc1, 0x48,
-	0x60, 0x71, 0x88, 0x68, 0x7a, 0x41, 0x1c, 0x81, 0x88, 0xf6, 0xc0, 0xc5, 0x82, 0x0b, 0x97, 0xca,
-	0x64, 0xb7, 0x65, 0x55, 0x67, 0x6d, 0xd9, 0x9b, 0x10, 0xbf, 0x10, 0x4f, 0xc6, 0x4b, 0x70, 0x43,
-	0x33, 0x76, 0x9d, 0x56, 0x11, 0x11, 0x37, 0xcf, 0xcc, 0x6f, 0xbe, 0xcc, 0x7c, 0x63, 0x07, 0x26,
-	0x6a, 0xab, 0x8c, 0xad, 0xe6, 0x45, 0x99, 0xdb, 0x1c, 0x87, 0x56, 0x9b, 0xba, 0x2c, 0xae, 0xa3,
-	0xdf, 0x02, 0xdc, 0xaf, 0x46, 0x5b, 0x9c, 0x82, 0xa3, 0x65, 0x28, 0x66, 0x22, 0xf6, 0x13, 0x47,
-	0x4b, 0x9c, 0x80, 0xd8, 0x85, 0xce, 0x4c, 0xc4, 0x22, 0x11, 0x3b, 0x8a, 0xea, 0xb0, 0xdf, 0x44,
-	0x35, 0x3e, 0x04, 0xef, 0xaa, 0x4c, 0xd7, 0x2a, 0x74, 0x67, 0x22, 0xf6, 0x92, 0x26, 0x40, 0x04,
-	0xb7, 0xba, 0xd1, 0x26, 0xf4, 0x58, 0x83, 0x9f, 0xf1, 0x31, 0x0c, 0xd2, 0x95, 0xd5, 0xb9, 0x09,
-	0x07, 0x9c, 0x6d, 0x23, 0x52, 0xa8, 0x0a, 0xa5, 0x64, 0x38, 0x64, 0xcd, 0x26, 0xc0, 0x37, 0xe0,
-	0x4b, 0x5d, 0xaa, 0xa6, 0x61, 0x34, 0x13, 0xf1, 0x74, 0x81, 0xf3, 0x76, 0xd2, 0xf9, 0xc7, 0xdb,
-	0x4a, 0xb2, 0x87, 0xf0, 0x25, 0xb8, 0x95, 0x96, 0x2a, 0xf4, 0xff, 0x09, 0x73, 0x3d, 0xfa, 0xe3,
-	0x80, 0xb7, 0x24, 0x03, 0xf0, 0x15, 0xb8, 0xb6, 0x2e, 0x14, 0x6f, 0x3a, 0x5d, 0x3c, 0xe8, 0x3a,
-	0xb8, 0x3a, 0xff, 0x52, 0x17, 0x2a, 0x61, 0x00, 0x63, 0x70, 0xb5, 0xd1, 0x96, 0x3d, 0x18, 0xdf,
-	0x91, 0x66, 0xf0, 0xc2, 0x68, 0x7b, 0xde, 0x4b, 0x98, 0xc0, 0x53, 0x18, 0xae, 0x72, 0x63, 0xd4,
-	0xca, 0xb2, 0x45, 0xe3, 0xc5, 0xa3, 0xfb, 0xf0, 0x87, 0xa6, 0x78, 0xde, 0x4b, 0x6e, 0x39, 0x12,
-	0x57, 0x3b, 0x6d, 0xd9, 0xc0, 0x03, 0xf1, 0xe5, 0xae, 0x11, 0x27, 0x82, 0xc7, 0x90, 0x99, 0x62,
-	0x57, 0x0f, 0xc7, 0x90, 0x99, 0xe2, 0x31, 0x64, 0xc6, 0x03, 0xaf, 0xf3, 0xad, 0x62, 0xa7, 0x0f,
-	0xc8, 0xcf, 0xf9, 0x96, 0x49, 0x22, 0x22, 0x09, 0x2e, 0x2d, 0x8a, 0x27, 0xe0, 0xd3, 0xaa, 0x97,
-	0xb4, 0x45, 0xd0, 0xc3, 0x00, 0x26, 0x1c, 0xb6, 0x43, 0x06, 0xa2, 0x03, 0x68, 0x92, 0xc0, 0xd9,
-	0xf3, 0x32, 0x53, 0x41, 0xbf, 0x0b, 0x49, 0x33, 0x70, 0x71, 0x0a, 0xd0, 0xc0, 0xeb, 0xc2, 0xd6,
-	0x81, 0xf7, 0x7e, 0x00, 0xae, 0x4c, 0x6d, 0x1a, 0xfd, 0x12, 0xe0, 0x77, 0xa6, 0xe1, 0x53, 0xf0,
-	0x8b, 0x2c, 0xad, 0x55, 0x79, 0xd9, 0xbd, 0x6e, 0xa3, 0x26, 0x71, 0x21, 0xf1, 0x0c, 0xbc, 0x8d,
-	0xd1, 0xb6, 0x0a, 0x9d, 0x59, 0x3f, 0x1e, 0x2f, 0x9e, 0x1d, 0x9a, 0x3e, 0xa7, 0x97, 0xb5, 0x5a,
-	0x1a, 0x5b, 0xd6, 0x49, 0xc3, 0x3e, 0xf9, 0x04, 0xb0, 0x4f, 0x62, 0x00, 0xfd, 0x1b, 0x55, 0xb7,
-	0xca, 0xf4, 0x88, 0x2f, 0xc0, 0xdb, 0xa6, 0xd9, 0x46, 0xb5, 0x97, 0x3c, 0xe9, 0x44, 0xa9, 0x2b,
-	0x69, 0x6a, 0xef, 0x9c, 0xb7, 0x22, 0x3a, 0x85, 0xc9, 0xdd, 0x7b, 0xe1, 0x73, 0x70, 0xe9, 0x17,
-	0x58, 0xeb, 0xa0, 0x8f, 0x4b, 0x51, 0xdc, 0xae, 0x46, 0x27, 0x3b, 0xba, 0x5a, 0x47, 0xd2, 0xc9,
-	0x8e, 0x93, 0xdf, 0x5a, 0x92, 0x4e, 0x76, 0xdc, 0xae, 0x7b, 0xdf, 0x8b, 0xf3, 0x1f, 0xdf, 0xcb,
-	0xeb, 0x05, 0xf8, 0x5d, 0x1e, 0x47, 0xe0, 0x66, 0xea, 0x8a, 0x2e, 0xef, 0x83, 0x57, 0xea, 0xeb,
-	0x1f, 0x74, 0xf2, 0x01, 0x38, 0x9b, 0x22, 0x70, 0xa8, 0x28, 0xf3, 0x9f, 0x26, 0xe8, 0x7f, 0x1f,
-	0xf0, 0x5f, 0xc6, 0xd9, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff, 0xaf, 0x29, 0x0a, 0x73, 0x42, 0x04,
-	0x00, 0x00,
+	// 1497 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x57, 0xdd, 0x6e, 0xdb, 0xc6,
+	0x12, 0x16, 0x45, 0x52, 0x12, 0x47, 0x92, 0x4d, 0xaf, 0x7f, 0xce, 0x9e, 0xe4, 0x18, 0x47, 0x61,
+	0x90, 0x13, 0x9d, 0x24, 0x30, 0x5a, 0x25, 0x40, 0x8a, 0x16, 0xbd, 0x68, 0x1b, 0xa7, 0x76, 0xda,
+	0xa2, 0x00, 0x93, 0x00, 0xc9, 0x95, 0x40, 0x8b, 0x6b, 0x8b, 0x15, 0x45, 0xaa, 0xe2, 0xca, 0x16,
+	0xdf, 0xa4, 0xaf, 0x11, 0xf4, 0xae, 0x57, 0x7d, 0x84, 0xde, 0xf5, 0x75, 0x8a, 0x99, 0x25, 0x29,
+	0xd1, 0x66, 0xd5, 0x04, 0xed, 0xdd, 0x7e, 0x33, 0xb3, 0xb3, 0xc3, 0xd9, 0x99, 0x9d, 0x8f, 0xd0,
+	0x11, 0x97, 0x22, 0x92, 0xc9, 0xd1, 0x6c, 0x1e, 0xcb, 0x98, 0x35, 0x65, 0x10, 0xa5, 0xf3, 0xd9,
+	0x85, 0xf3, 0xce, 0x04, 0xe3, 0x75, 0x14, 0x48, 0xb6, 0x05, 0xf5, 0xc0, 0xe7, 0x5a, 0x4f, 0xeb,
+	0x5b, 0x6e, 0x3d, 0xf0, 0x59, 0x07, 0xb4, 0x25, 0xaf, 0xf7, 0xb4, 0xbe, 0xe6, 0x6a, 0x4b, 0x44,
+	0x29, 0xd7, 0x15, 0x4a, 0xd9, 0x1e, 0x98, 0xe7, 0x73, 0x6f, 0x2a, 0xb8, 0xd1, 0xd3, 0xfa, 0xa6,
+	0xab, 0x00, 0x63, 0x60, 0x24, 0x93, 0x20, 0xe2, 0x26, 0xf9, 0xa0, 0x35, 0x3b, 0x80, 0x86, 0x37,
+	0x92, 0x41, 0x1c, 0xf1, 0x06, 0x49, 0x33, 0x84, 0x1e, 0x92, 0x99, 0x10, 0x3e, 0x6f, 0x92, 0x4f,
+	0x05, 0xd8, 0xff, 0xc0, 0x48, 0x02, 0x5f, 0x70, 0xab, 0xa7, 0xf5, 0xb7, 0x06, 0xec, 0x28, 0x0b,
+	0xf2, 0xe8, 0x59, 0x30, 0x17, 0xb4, 0xcf, 0x25, 0x3d, 0xbb, 0x05, 0xad, 0x44, 0xfc, 0xb8, 0x10,
+	0xd1, 0x48, 0x70, 0xe8, 0x69, 0xfd, 0xae, 0x5b, 0x60, 0xf6, 0x5f, 0x68, 0x07, 0xd1, 0x6c, 0x21,
+	0x87, 0x32, 0x18, 0x4d, 0x12, 0xde, 0x26, 0x35, 0x90, 0xe8, 0x15, 0x4a, 0x30, 0xa4, 0xb9, 0xe7,
+	0x07, 0x8b, 0x84, 0x77, 0xe8, 0xec, 0x0c, 0x61, 0xf8, 0x53, 0x2f, 0x49, 0x78, 0x97, 0xa4, 0xb4,
+	0x66, 0xfb, 0xd0, 0x98, 0xc6, 0x97, 0x62, 0xb8, 0xe4, 0x5b, 0x2a, 0x4e, 0x44, 0x6f, 0x0a, 0x71,
+	0xca, 0xb7, 0x57, 0xe2, 0xb7, 0xe8, 0xf9, 0x4a, 0x78, 0xb3, 0x38, 0xe2, 0xb6, 0xfa, 0x58, 0x85,
+	0x30, 0xdc, 0x51, 0x1c, 0x87, 0x7e, 0x7c, 0x15, 0xf1, 0x1d, 0x15, 0x6e, 0x8e, 0x29, 0x11, 0x57,
+	0x41, 0x74, 0xc1, 0x19, 0x29, 0x14, 0xa0, 0x54, 0xca, 0x45, 0xc4, 0x77, 0x49, 0x48, 0x6b, 0x3c,
+	0x74, 0xb6, 0x48, 0xc6, 0xc3, 0x25, 0xdf, 0x53, 0x87, 0x22, 0x7a, 0x53, 0x88, 0x53, 0xbe, 0xbf,
+	0x12, 0xbf, 0x65, 0xbb, 0x60, 0x7a, 0xc1, 0x74, 0xb8, 0xe4, 0x07, 0xea, 0x73, 0xbc, 0x60, 0xfa,
+	0x26, 0x17, 0xa6, 0xfc, 0x5f, 0x85, 0x90, 0xa2, 0x1e, 0x0b, 0x2f, 0x94, 0x63, 0xce, 0xe9, 0x36,
+	0x33, 0xc4, 0x0e, 0x01, 0xa6, 0xde, 0x72, 0x98, 0xe9, 0xfe, 0x4d, 0x3a, 0x6b, 0xea, 0x2d, 0x4f,
+	0x94, 0x9a, 0x43, 0x73, 0x2e, 0x92, 0x99, 0x77, 0x15, 0xf1, 0x5b, 0x14, 0x65, 0x0e, 0xd9, 0x43,
+	0xb0, 0x82, 0x08, 0x8b, 0x2d, 0x9e, 0xa7, 0xfc, 0x76, 0x4f, 0xef, 0xb7, 0x07, 0xdd, 0xe2, 0x2a,
+	0x5f, 0x86, 0xb1, 0x74, 0x57, 0x7a, 0xfc, 0xfe, 0xb3, 0x38, 0x4e, 0x24, 0xff, 0x8f, 0xfa, 0x7e,
+	0x02, 0x18, 0x53, 0x32, 0x0e, 0x44, 0xe8, 0xf3, 0x43, 0x12, 0x67, 0xe8, 0x85, 0xd1, 0x6a, 0xd9,
+	0x96, 0xf3, 0x11, 0x18, 0xe8, 0x06, 0xb3, 0x14, 0x48, 0x31, 0xcd, 0x8a, 0x96, 0xd6, 0xe8, 0x6f,
+	0x14, 0x2f, 0x22, 0x49, 0xa5, 0x6b, 0xba, 0x0a, 0x38, 0xcf, 0xc1, 0x38, 0x45, 0xed, 0xf5, 0x22,
+	0x67, 0x60, 0x4c, 0x82, 0xc8, 0x27, 0x63, 0xcb, 0xa5, 0xb5, 0x2a, 0x7c, 0xbd, 0x54, 0xf8, 0x86,
+	0x42, 0xa9, 0xf3, 0x8b, 0x06, 0x8d, 0xef, 0xcf, 0x7e, 0x10, 0x23, 0xf9, 0x77, 0x5d, 0xd1, 0xc5,
+	0x4b, 0x4f, 0x8a, 0xac, 0x5d, 0x14, 0x40, 0xa9, 0x0c, 0xa6, 0x62, 0x4e, 0xed, 0xd2, 0x75, 0x15,
+	0x40, 0xe9, 0x55, 0xe0, 0xcb, 0x71, 0xde, 0x2d, 0x04, 0xd4, 0xc5, 0x05, 0x17, 0x63, 0xc9, 0x5b,
+	0xaa, 0x90, 0x15, 0x42, 0xb9, 0xf4, 0xe6, 0x17, 0x42, 0x52, 0x1f, 0x59, 0x6e, 0x86, 0x9c, 0x77,
+	0x0d, 0x30, 0x8f, 0x31, 0xef, 0xec, 0x3e, 0x18, 0x32, 0x9d, 0x09, 0x8a, 0x7e, 0x6b, 0xb0, 0x5b,
+	0x5c, 0x0e, 0x69, 0x8f, 0x5e, 0xa5, 0x33, 0xe1, 0x92, 0x01, 0xeb, 0x83, 0x11, 0x44, 0x81, 0x4a,
+	0x66, 0x7b, 0xc0, 0xca, 0x86, 0xa7, 0x51, 0x20, 0x4f, 0x6a, 0x2e, 0x59, 0xb0, 0x8f, 0xa1, 0x39,
+	0x8a, 0xa3, 0x48, 0x8c, 0x24, 0x7d, 0x70, 0x7b, 0xb0, 0x5f, 0x36, 0xfe, 0x4a, 0x29, 0x4f, 0x6a,
+	0x6e, 0x6e, 0x87, 0xce, 0xc5, 0x32, 0x90, 0xdc, 0xa8, 0x72, 0x7e, 0xbc, 0x54, 0xce, 0xd1, 0x82,
+	0xc2, 0xf0, 0x43, 0x95, 0xaa, 0x9b, 0x61, 0xf8, 0xa1, 0xa0, 0x30, 0xfc, 0x90, 0x02, 0xc6, 0x5e,
+	0xe4, 0x8d, 0x2a, 0xcb, 0xef, 0xe2, 0x4b, 0xb2, 0x44, 0x0b, 0xf6, 0x04, 0x5a, 0x49, 0xe4, 0xcd,
+	0x92, 0x71, 0x2c, 0x29, 0xad, 0xed, 0xc1, 0x41, 0xd9, 0xfa, 0x65, 0xa6, 0x3d, 0xa9, 0xb9, 0x85,
+	0x25, 0x7b, 0x08, 0xa6, 0x2f, 0x42, 0xe9, 0x51, 0xca, 0xdb, 0xd7, 0x53, 0xf7, 0x0c, 0x55, 0x27,
+	0x35, 0x57, 0xd9, 0xb0, 0x7b, 0xa0, 0x7b, 0xa3, 0x09, 0xdd, 0x42, 0x7b, 0xb0, 0x53, 0x36, 0xfd,
+	0x62, 0x34, 0x39, 0xa9, 0xb9, 0xa8, 0x67, 0x47, 0xd0, 0xf0, 0xa4, 0x44, 0x4b, 0x20, 0xcb, 0xbd,
+	0x6b, 0x96, 0xa4, 0x3b, 0xa9, 0xb9, 0x99, 0x95, 0x8a, 0xc1, 0x93, 0x63, 0xde, 0xae, 0x8e, 0xc1,
+	0x93, 0x63, 0x15, 0x83, 0x27, 0xc7, 0x18, 0xc3, 0x22, 0x11, 0xbc, 0x53, 0x15, 0xc3, 0xeb, 0x04,
+	0xd3, 0x81, 0x7a, 0xcc, 0x46, 0x10, 0x49, 0x31, 0xf7, 0x46, 0x92, 0x77, 0xab, 0xb2, 0x71, 0x9a,
+	0x69, 0x31, 0x1b, 0xb9, 0xa5, 0xf3, 0xbb, 0x06, 0x06, 0x56, 0x0b, 0xeb, 0x82, 0x85, 0xf5, 0x32,
+	0xc4, 0x52, 0xb0, 0x6b, 0xcc, 0x86, 0x0e, 0xc1, 0xec, 0xa6, 0x6d, 0xad, 0x30, 0xc0, 0xeb, 0xb4,
+	0xeb, 0x2b, 0x7b, 0x3f, 0x14, 0xb6, 0x5e, 0x40, 0xbc, 0x18, 0xdb, 0x60, 0x5b, 0x00, 0xca, 0x78,
+	0x3a, 0x93, 0xa9, 0x6d, 0xb2, 0x1d, 0xe8, 0x12, 0xce, 0x6f, 0xc1, 0x6e, 0x14, 0x26, 0x94, 0x68,
+	0xbb, 0xc9, 0x3a, 0xd0, 0x22, 0xec, 0x8d, 0x26, 0x76, 0x8b, 0x6d, 0x43, 0x5b, 0x21, 0x4a, 0x98,
+	0x6d, 0xad, 0x99, 0x7b, 0x72, 0x6c, 0x43, 0x61, 0xbe, 0x48, 0x84, 0xdd, 0x2e, 0xfc, 0xe7, 0xdf,
+	0x65, 0x77, 0xbe, 0x6c, 0x80, 0xe1, 0x7b, 0xd2, 0x73, 0x7e, 0xd3, 0xc0, 0x2a, 0x8a, 0x9d, 0xdd,
+	0x06, 0x6b, 0x16, 0x7a, 0xa9, 0x98, 0x0f, 0x8b, 0xd6, 0x6f, 0x29, 0xc1, 0xa9, 0xcf, 0x1e, 0x83,
+	0xb9, 0x88, 0x02, 0x99, 0xf0, 0x3a, 0x3d, 0x79, 0x87, 0x37, 0x9b, 0xe5, 0x08, 0x07, 0x6d, 0x72,
+	0x1c, 0xc9, 0x79, 0xea, 0x2a, 0x5b, 0xec, 0xec, 0x50, 0x5c, 0x8a, 0x90, 0x9a, 0xc6, 0x72, 0x15,
+	0xa0, 0xe7, 0x1f, 0x87, 0x23, 0x76, 0x86, 0xee, 0xd2, 0xfa, 0xd6, 0xd7, 0x00, 0xab, 0xed, 0xcc,
+	0x06, 0x7d, 0x22, 0xd2, 0x2c, 0x06, 0x5c, 0xb2, 0xbb, 0x60, 0x5e, 0x7a, 0xe1, 0x42, 0x64, 0xbd,
+	0xba, 0x7a, 0x71, 0x71, 0x97, 0xab, 0x74, 0x9f, 0xd6, 0x3f, 0xd1, 0x9c, 0x63, 0xe8, 0xac, 0x77,
+	0x24, 0xbb, 0x03, 0x06, 0xc6, 0xc2, 0xb5, 0xaa, 0x7d, 0xa4, 0xc2, 0x78, 0xce, 0xe7, 0xf1, 0x34,
+	0x7f, 0xdb, 0x70, 0xed, 0xf4, 0xc1, 0x2a, 0x1a, 0x75, 0x63, 0x62, 0x9c, 0x67, 0x79, 0x0a, 0xb1,
+	0x41, 0x37, 0xa6, 0x70, 0x7d, 0xae, 0xd7, 0xcb, 0x73, 0xdd, 0x39, 0x07, 0xab, 0x68, 0xe2, 0xf7,
+	0xf7, 0xa2, 0x97, 0xbd, 0xa8, 0x17, 0xd9, 0x28, 0xbd, 0xc8, 0xa6, 0x42, 0xe9, 0x0b, 0xa3, 0x55,
+	0xb7, 0x75, 0xe7, 0x67, 0x1d, 0xba, 0xa5, 0xfe, 0xc7, 0xaf, 0x47, 0x2e, 0x41, 0xe7, 0x18, 0x2e,
+	0xad, 0xd9, 0xd3, 0xf2, 0x65, 0xdf, 0xa9, 0x7e, 0x3a, 0x2a, 0x2e, 0xfc, 0x29, 0x98, 0x38, 0xa7,
+	0x12, 0xae, 0x6f, 0xdc, 0x88, 0xd3, 0x2a, 0xdf, 0x48, 0xf6, 0xec, 0x73, 0x68, 0xc6, 0x34, 0x79,
+	0x12, 0x6e, 0xd0, 0xd6, 0xbb, 0x7f, 0xb2, 0x55, 0xcd, 0xa7, 0x6c, 0x73, 0xbe, 0xe7, 0x1f, 0x2b,
+	0x1f, 0x74, 0xb4, 0x0a, 0xee, 0x43, 0x1c, 0xe1, 0xae, 0x75, 0x47, 0xdf, 0x40, 0x67, 0x3d, 0xd4,
+	0x0a, 0x57, 0xf7, 0xca, 0xae, 0xb6, 0x0b, 0x57, 0x6a, 0xdf, 0x7a, 0x51, 0xff, 0x64, 0x80, 0x85,
+	0x91, 0xd2, 0x0b, 0x7c, 0x63, 0x36, 0x1f, 0x40, 0xe3, 0x1c, 0xf9, 0x43, 0x92, 0x55, 0x55, 0x86,
+	0x36, 0xce, 0xe7, 0x15, 0x73, 0x35, 0x4b, 0xcc, 0x35, 0xe7, 0xa8, 0xcd, 0x0f, 0xe0, 0xa8, 0xad,
+	0xcd, 0x1c, 0xd5, 0xba, 0xc1, 0x51, 0x57, 0xbc, 0x13, 0xaa, 0x79, 0x67, 0xbb, 0x9a, 0x77, 0x76,
+	0x4a, 0xbc, 0xb3, 0xe0, 0x96, 0xdd, 0x75, 0x6e, 0x59, 0x30, 0xc3, 0xad, 0x2a, 0x66, 0xb8, 0xbd,
+	0xc6, 0x0c, 0x73, 0x16, 0x6a, 0xaf, 0xb1, 0xd0, 0x15, 0x5b, 0xdc, 0xd9, 0xc0, 0x16, 0xd9, 0x06,
+	0xb6, 0xb8, 0xbb, 0x81, 0x2d, 0xee, 0xbd, 0x2f, 0x5b, 0xdc, 0xaf, 0x66, 0x8b, 0x07, 0xd7, 0xd8,
+	0x62, 0xc3, 0x6e, 0x3a, 0xbf, 0xd6, 0x01, 0x56, 0xd3, 0xb9, 0xb2, 0x9b, 0x19, 0x18, 0x67, 0x5e,
+	0xa2, 0xea, 0xcc, 0x70, 0x69, 0xcd, 0xee, 0x43, 0x53, 0xe0, 0x3c, 0x10, 0x3e, 0xd7, 0xaf, 0x45,
+	0x45, 0x2d, 0x91, 0x6b, 0xd9, 0x23, 0x68, 0x8e, 0xc6, 0x5e, 0x74, 0x41, 0xef, 0xb5, 0x5e, 0x62,
+	0x1d, 0x45, 0x45, 0xba, 0xb9, 0x09, 0x1e, 0x15, 0x8a, 0x73, 0xc9, 0xcd, 0x9e, 0x8e, 0x4f, 0x29,
+	0xae, 0xd9, 0x00, 0xba, 0xd4, 0xe3, 0xc3, 0xfc, 0xc0, 0x46, 0x4f, 0xbf, 0xd9, 0x3a, 0x9d, 0x20,
+	0x6b, 0x3b, 0x3a, 0xf5, 0x10, 0x40, 0xed, 0x21, 0x6f, 0x4d, 0xf2, 0x66, 0x91, 0xe4, 0x5b, 0x74,
+	0xf9, 0xff, 0xd5, 0x6b, 0xd1, 0xea, 0xe9, 0x55, 0xcd, 0x93, 0xeb, 0xd9, 0x1d, 0xe8, 0x64, 0x4b,
+	0xe5, 0xcb, 0x22, 0x5f, 0xed, 0x4c, 0x86, 0xde, 0x9c, 0x27, 0xd0, 0xca, 0x49, 0x4b, 0x65, 0xfe,
+	0x8a, 0x29, 0x56, 0x5f, 0x9b, 0x62, 0xce, 0x03, 0x68, 0xaf, 0x11, 0x98, 0xcd, 0x33, 0xe2, 0x79,
+	0x71, 0x47, 0x48, 0x5a, 0x36, 0x99, 0xa2, 0x72, 0x12, 0x84, 0xa1, 0x52, 0xaa, 0x03, 0x5b, 0x4a,
+	0x70, 0xea, 0x3b, 0x9f, 0x65, 0x91, 0xbe, 0x4e, 0xfe, 0x62, 0x48, 0x60, 0x6d, 0x87, 0x71, 0xfe,
+	0x9b, 0x40, 0x6b, 0xe7, 0x11, 0x74, 0x4b, 0x5c, 0x67, 0xa3, 0x87, 0x07, 0x03, 0xb0, 0x8a, 0x9e,
+	0x67, 0x2d, 0x75, 0xad, 0x76, 0x8d, 0x59, 0x60, 0xce, 0x91, 0x86, 0xdb, 0x1a, 0x6b, 0x40, 0x7d,
+	0x31, 0xb3, 0xeb, 0xa8, 0xc4, 0x7f, 0x3d, 0x5b, 0x3f, 0x6b, 0xd0, 0xdf, 0xf7, 0xe3, 0x3f, 0x06,
+	0x00, 0xaa, 0x79, 0xae, 0x7a, 0x8d, 0x0f, 0x00, 0x00,
 }
@@ -1,10 +1,16 @@
 package internal
 
 import (
+	"image"
 	"log"
+	"math"
 	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	uuid "github.com/satori/go.uuid"
 )
 
@@ -12,11 +18,73 @@ import (
 type World struct {
 	Replica bool
 	Units   map[string]*Unit
+	Items   map[string]*Item
+	Objects map[string]*Object
 	MyID    string
+	Tick    uint64
+
+	// Seed seeds the randomness of the simulation on the server, so a
+	// world stepped through the same events from the same state and seed
+	// always ends up the same.
+	Seed int64
+
+	// Level is the map units collide with, and Footprints the colliding
+	// part of every skin. Units move freely without a level.
+	Level      *Level
+	Footprints map[string]image.Rectangle
+
+	// InterpolationDelay is how far behind the server remote units are
+	// drawn on a replica. DefaultInterpolationDelay is used when zero.
+	InterpolationDelay time.Duration
+
+	// Events received from other goroutines, applied by the next Step.
+	queueMu sync.Mutex
+	queue   []*Event
+
+	// State published at the end of every Step for other goroutines.
+	publishedMu sync.RWMutex
+	published   *EventSnapshot
+
+	// Client-side prediction state, only used by replicas.
+	sequence uint32
+	acked    *input
+	pending  []*input
+
+	// Entity interpolation state, only used by replicas.
+	clock   time.Time
+	samples map[string][]sample
+
+	// Snapshots that deltas from the server may refer to, only used by
+	// replicas.
+	baselines map[uint64]*EventSnapshot
+	newest    uint64
+	announced uint64
+
+	// Events for every player, collected by the server.
+	outbox []*Event
+
+	// Units controlled by the server and the pathfinder they share, only
+	// used by the server.
+	npcs  map[string]*NPC
+	paths *Pathfinder
+
+	// Tiles blocked by closed doors and tiles with pits, kept up to date
+	// by fence.
+	barriers map[image.Point]bool
+	pits     map[image.Point]bool
+
+	// Units that left for other levels, only used by the server.
+	departures []Departure
+
+	// The random numbers drawn from Seed and the last id given to a unit
+	// or item, only used by the server.
+	rand   *rand.Rand
+	serial uint64
 }
 
-func (world *World) AddPlayer() string {
-	skins := []string{"big_demon", "big_zombie", "elf_f"}
+// AddPlayer creates a unit for a new player. The unit joins the world on
+// the next Step; the returned copy is the caller's to keep.
+func (world *World) AddPlayer() *Unit {
 	id := uuid.NewV4().String()
 	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
 	unit := &Unit{
@@ -24,78 +92,414 @@ func (world *World) AddPlayer() string {
 		X:      rnd.Float64()*300 + 10,
 		Y:      rnd.Float64()*220 + 10,
 		Frame:  int32(rnd.Intn(4)),
-		Skin:   skins[rnd.Intn(len(skins))],
+		Skin:   PlayerSkins[rnd.Intn(len(PlayerSkins))],
 		Action: "idle",
 		Speed:  1,
+		Weapon: PlayerWeapons[rnd.Intn(len(PlayerWeapons))],
+		AimX:   1,
 	}
-	world.Units[id] = unit
+	world.shape(unit)
+	heal(unit)
+	world.HandleEvent(&Event{
+		Type: Event_type_connect,
+		Data: &Event_Connect{
+			Connect: &EventConnect{Unit: proto.Clone(unit).(*Unit)},
+		},
+	})
 
-	return id
+	return unit
 }
 
+// HandleEvent queues an event for the next Step. It is safe to call from
+// any goroutine.
 func (world *World) HandleEvent(event *Event) {
-	log.Println(event.GetType())
-	log.Println(event.GetData())
+	world.queueMu.Lock()
+	world.queue = append(world.queue, event)
+	world.queueMu.Unlock()
+}
+
+// Snapshot returns the state of the world as of the last Step. It is safe
+// to call from any goroutine; the entities are shared and must not be
+// modified, but the maps belong to the caller.
+func (world *World) Snapshot() *EventSnapshot {
+	world.publishedMu.RLock()
+	defer world.publishedMu.RUnlock()
+
+	snapshot := &EventSnapshot{
+		Units:   map[string]*Unit{},
+		Items:   map[string]*Item{},
+		Objects: map[string]*Object{},
+	}
+	if world.published != nil {
+		snapshot.Tick = world.published.Tick
+		for id, unit := range world.published.Units {
+			snapshot.Units[id] = unit
+		}
+		for id, item := range world.published.Items {
+			snapshot.Items[id] = item
+		}
+		for id, object := range world.published.Objects {
+			snapshot.Objects[id] = object
+		}
+	}
+
+	return snapshot
+}
+
+// publish makes a copy of the current state available to Snapshot. Items
+// never change once dropped, so they are shared.
+func (world *World) publish() {
+	snapshot := &EventSnapshot{
+		Tick:    world.Tick,
+		Units:   make(map[string]*Unit, len(world.Units)),
+		Items:   make(map[string]*Item, len(world.Items)),
+		Objects: make(map[string]*Object, len(world.Objects)),
+	}
+	for id, unit := range world.Units {
+		snapshot.Units[id] = proto.Clone(unit).(*Unit)
+	}
+	for id, item := range world.Items {
+		snapshot.Items[id] = item
+	}
+	for id, object := range world.Objects {
+		snapshot.Objects[id] = proto.Clone(object).(*Object)
+	}
+
+	world.publishedMu.Lock()
+	world.published = snapshot
+	world.publishedMu.Unlock()
+}
+
+// apply changes the world according to an event. It must only be called
+// from the goroutine that runs Step.
+func (world *World) apply(event *Event) {
+	if event.GetType() != Event_type_snapshot && event.GetType() != Event_type_delta {
+		log.Println(event.GetType())
+		log.Println(event.GetData())
+	}
 
 	switch event.GetType() {
 	case Event_type_connect:
 		data := event.GetConnect()
+		if !world.Replica {
+			world.arrive(data.Unit, data.From)
+		}
 		world.Units[data.Unit.Id] = data.Unit
 
 	case Event_type_init:
 		data := event.GetInit()
 		if world.Replica {
+			world.enter(data.Level, data.Seed)
 			world.MyID = data.PlayerId
 			world.Units = data.Units
+			world.samples = nil
+			world.baselines = nil
+			world.newest = 0
+			world.announced = 0
+			world.clock = time.Time{}
 		}
 
 	case Event_type_exit:
 		data := event.GetExit()
 		delete(world.Units, data.PlayerId)
+		delete(world.samples, data.PlayerId)
+
+	case Event_type_move, Event_type_idle, Event_type_attack, Event_type_use, Event_type_interact:
+		world.applyInput(event)
+
+	case Event_type_snapshot:
+		data := event.GetSnapshot()
+		if world.Replica {
+			world.keepBaseline(data, 0)
+			world.reconcile(data)
+		}
+
+	case Event_type_death:
+		data := event.GetDeath()
+		// Others vanish right away; the own unit stays to wait for the
+		// respawn.
+		if world.Replica && data.PlayerId != world.MyID {
+			delete(world.Units, data.PlayerId)
+			delete(world.samples, data.PlayerId)
+		}
+
+	case Event_type_delta:
+		data := event.GetDelta()
+		if world.Replica {
+			base, ok := world.baselines[data.Base]
+			if !ok {
+				log.Println("DELTA ON UNKNOWN BASE: ", data.Base)
+				return
+			}
+			snapshot := Patch(base, data)
+			world.keepBaseline(snapshot, data.Base)
+			world.reconcile(snapshot)
+		}
 
+	default:
+		log.Println("UNKNOWN EVENT: ", event)
+	}
+}
+
+// Step advances the simulation by exactly one tick. Events queued since
+// the previous step are applied first, in the order they arrived, and units
+// are then moved in id order. Randomness comes from Seed and ids from a
+// counter, so the same events on the same state always give the same world.
+func (world *World) Step(tick uint64) {
+	world.queueMu.Lock()
+	queue := world.queue
+	world.queue = nil
+	world.queueMu.Unlock()
+
+	// Arriving units look for the ladders they came by.
+	if !world.Replica {
+		world.furnish()
+	}
+	for _, event := range queue {
+		world.apply(event)
+	}
+
+	world.Tick = tick
+	if !world.Replica {
+		world.populate()
+		world.think()
+	}
+	for _, id := range sortedIDs(world.Units) {
+		unit, ok := world.Units[id]
+		if !ok {
+			continue
+		}
+		world.recover(unit)
+		// Remote units on a replica only move with the snapshots.
+		if world.Replica && id != world.MyID {
+			continue
+		}
+		world.advance(unit)
+	}
+	world.separate()
+	if !world.Replica {
+		world.collect()
+		world.operate()
+	}
+	if world.Replica {
+		world.countInputTicks()
+	}
+
+	world.publish()
+}
+
+// random returns the source of randomness of the simulation, seeded with
+// Seed on first use.
+func (world *World) random() *rand.Rand {
+	if world.rand == nil {
+		world.rand = rand.New(rand.NewSource(world.Seed))
+	}
+	return world.rand
+}
+
+// newID returns an id for a unit or item of the given kind that no other
+// one of the world has.
+func (world *World) newID(kind string) string {
+	world.serial++
+	return kind + "-" + strconv.FormatUint(world.serial, 10)
+}
+
+// sortedIDs returns the ids of the units in a stable order.
+func sortedIDs(units map[string]*Unit) []string {
+	ids := make([]string, 0, len(units))
+	for id := range units {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// applyInput applies a move, idle, attack, use or interact intent to the
+// unit it belongs to.
+func (world *World) applyInput(event *Event) {
+	switch event.GetType() {
 	case Event_type_move:
 		data := event.GetMove()
 		unit := world.Units[data.PlayerId]
-		unit.Action = UnitActionMove
-		unit.Direction = data.Direction
+		if unit == nil {
+			return
+		}
+		unit.MoveX, unit.MoveY = normalize(data.X, data.Y)
+		unit.Action = action(unit)
+		unit.Sequence = data.Sequence
+		unit.InputTicks = 0
 
 	case Event_type_idle:
 		data := event.GetIdle()
 		unit := world.Units[data.PlayerId]
-		unit.Action = UnitActionIdle
+		if unit == nil {
+			return
+		}
+		unit.MoveX, unit.MoveY = 0, 0
+		unit.Action = action(unit)
+		unit.Sequence = data.Sequence
+		unit.InputTicks = 0
+
+	case Event_type_attack:
+		data := event.GetAttack()
+		unit := world.Units[data.PlayerId]
+		if unit == nil {
+			return
+		}
+		world.attack(unit)
+
+	case Event_type_use:
+		data := event.GetUse()
+		unit := world.Units[data.PlayerId]
+		if unit == nil {
+			return
+		}
+		world.use(unit, int(data.Slot))
 
+	case Event_type_interact:
+		data := event.GetInteract()
+		unit := world.Units[data.PlayerId]
+		if unit == nil {
+			return
+		}
+		world.interact(unit)
+	}
+}
+
+// action tells what a unit is doing from its state: waiting to respawn,
+// staggering from a hit, walking where its input points or standing still.
+func action(unit *Unit) string {
+	switch {
+	case dead(unit):
+		return UnitActionDead
+	case unit.Stun > 0:
+		return UnitActionHit
+	case unit.MoveX != 0 || unit.MoveY != 0:
+		return UnitActionMove
 	default:
-		log.Println("UNKNOWN EVENT: ", event)
+		return UnitActionIdle
 	}
 }
 
-func (world *World) Evolve() {
-	ticker := time.NewTicker(time.Second / 60)
-
-	for {
-		select {
-		case <-ticker.C:
-			for _, unit := range world.Units {
-				if unit.Action == UnitActionMove {
-					switch unit.Direction {
-					case Direction_left:
-						unit.X -= unit.Speed
-						unit.Side = Direction_left
-					case Direction_right:
-						unit.X += unit.Speed
-						unit.Side = Direction_right
-					case Direction_up:
-						unit.Y -= unit.Speed
-					case Direction_down:
-						unit.Y += unit.Speed
-					default:
-						log.Println("UNKNOWN DIRECTION: ", unit.Direction)
-					}
-				}
-			}
+// advance moves the unit by a single tick.
+func (world *World) advance(unit *Unit) {
+	unit.InputTicks++
+	if unit.Action != UnitActionMove {
+		return
+	}
+
+	world.move(unit, unit.MoveX*speed(unit), unit.MoveY*speed(unit))
+	if unit.MoveX < 0 {
+		unit.Side = Direction_left
+	} else if unit.MoveX > 0 {
+		unit.Side = Direction_right
+	}
+}
+
+// normalize turns an input vector from any device into a direction that is
+// at most 1 long, so diagonals are no faster than straight lines and
+// analog sticks can walk slower.
+func normalize(x, y float64) (float64, float64) {
+	if math.IsNaN(x) || math.IsNaN(y) || math.IsInf(x, 0) || math.IsInf(y, 0) {
+		return 0, 0
+	}
+
+	length := math.Hypot(x, y)
+	if length > 1 {
+		return x / length, y / length
+	}
+	return x, y
+}
+
+// reconcile overwrites the replica state with an authoritative snapshot
+// from the server. Units keep their identity so the renderer does not
+// notice the swap; units missing from the snapshot are gone.
+func (world *World) reconcile(snapshot *EventSnapshot) {
+	world.syncClock(snapshot.Tick, time.Now())
+
+	world.Items = make(map[string]*Item, len(snapshot.Items))
+	for id, item := range snapshot.Items {
+		world.Items[id] = item
+	}
+	world.Objects = make(map[string]*Object, len(snapshot.Objects))
+	for id, object := range snapshot.Objects {
+		world.Objects[id] = object
+	}
+	world.fence()
+
+	for id, unit := range snapshot.Units {
+		if id != world.MyID {
+			world.remember(snapshot.Tick, unit)
+		}
+
+		local, ok := world.Units[id]
+		if !ok {
+			world.Units[id] = proto.Clone(unit).(*Unit)
+			continue
+		}
+		local.X = unit.X
+		local.Y = unit.Y
+		local.Frame = unit.Frame
+		local.Skin = unit.Skin
+		local.Action = unit.Action
+		local.Speed = unit.Speed
+		local.MoveX = unit.MoveX
+		local.MoveY = unit.MoveY
+		local.Side = unit.Side
+		local.Sequence = unit.Sequence
+		local.InputTicks = unit.InputTicks
+		local.Weapon = unit.Weapon
+		local.Stun = unit.Stun
+		local.Health = unit.Health
+		local.MaxHealth = unit.MaxHealth
+		local.Respawn = unit.Respawn
+		local.Inventory = unit.Inventory
+		local.Boost = unit.Boost
+		local.Shield = unit.Shield
+		// The swings of the local player are predicted and shown at once,
+		// the server's copy of them is late.
+		if id != world.MyID {
+			local.Swing = unit.Swing
+			local.AimX = unit.AimX
+			local.AimY = unit.AimY
+		}
+
+		if id == world.MyID {
+			world.replay(local)
+		}
+	}
+
+	for id := range world.Units {
+		if _, ok := snapshot.Units[id]; !ok {
+			delete(world.Units, id)
+			delete(world.samples, id)
 		}
 	}
 }
 
+// PlayerSkins are the sprites players are randomly given.
+var PlayerSkins = []string{"big_demon", "big_zombie", "elf_f"}
+
+// SkinMass tells how hard units of a skin are to push around. Skins that
+// are not listed weigh 1.
+var SkinMass = map[string]float64{
+	"big_demon":  4,
+	"big_zombie": 3,
+	"elf_f":      1,
+	"ogre":       4,
+	"chort":      2,
+	"mimic":      2,
+}
+
+// TickRate is the number of simulation ticks per second.
+const TickRate = 60
+
+// SnapshotRate is how many times per second the server sends its state to
+// the replicas.
+const SnapshotRate = 20
+
 const UnitActionMove = "run"
 const UnitActionIdle = "idle"
+const UnitActionHit = "hit"
+const UnitActionDead = "dead"
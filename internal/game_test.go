@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"image"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// testLevel builds a level from rows of tiles, '#' for walls and anything
+// else for floor.
+func testLevel(rows ...string) *Level {
+	layer := make([][]string, len(rows))
+	for j, row := range rows {
+		layer[j] = make([]string, len(row))
+		for i, tile := range row {
+			layer[j][i] = "floor_1"
+			if tile == '#' {
+				layer[j][i] = "wall_mid"
+			}
+		}
+	}
+	return &Level{Name: "test", Layers: [][][]string{layer}}
+}
+
+// testFootprints gives every skin the footprint of a 16x28 sprite, like the
+// ones of the players.
+func testFootprints() map[string]image.Rectangle {
+	footprints := map[string]image.Rectangle{}
+	for _, skin := range PlayerSkins {
+		footprints[skin] = image.Rect(2, 21, 14, 28)
+	}
+	for skin := range Monsters {
+		footprints[skin] = image.Rect(2, 21, 14, 28)
+	}
+	return footprints
+}
+
+func move(id string, x, y float64) *Event {
+	return &Event{
+		Type: Event_type_move,
+		Data: &Event_Move{
+			Move: &EventMove{PlayerId: id, X: x, Y: y},
+		},
+	}
+}
+
+// TestConcurrentClients runs many clients against a world being stepped, as
+// the server does, so that go test -race can catch shared state.
+func TestConcurrentClients(t *testing.T) {
+	// Every applied event is logged.
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	const clients = 32
+	const ticks = 200
+
+	world := &World{
+		Units:      map[string]*Unit{},
+		Footprints: testFootprints(),
+		Level: testLevel(
+			"####################",
+			"#..................#",
+			"#..................#",
+			"#..................#",
+			"#..................#",
+			"#..................#",
+			"#..................#",
+			"####################",
+		),
+	}
+
+	done := make(chan struct{})
+	var joined, wg sync.WaitGroup
+	for n := 0; n < clients; n++ {
+		joined.Add(1)
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+
+			unit := world.AddPlayer()
+			joined.Done()
+			for k := 0; ; k++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				world.HandleEvent(move(unit.Id, float64(n%3-1), float64(k%3-1)))
+				time.Sleep(time.Millisecond)
+				snapshot := world.Snapshot()
+				view := Interest(snapshot, NewGrid(snapshot.Units, InterestRadius), unit.Id)
+				for _, other := range view.Units {
+					_ = other.X + other.Y
+				}
+			}
+		}(n)
+	}
+
+	joined.Wait()
+	for tick := uint64(1); tick <= ticks; tick++ {
+		world.Step(tick)
+		time.Sleep(time.Millisecond)
+		world.Events()
+		world.Departures()
+	}
+	close(done)
+	wg.Wait()
+
+	if got := len(world.Snapshot().Units); got < clients {
+		t.Errorf("snapshot has %d units, want at least %d", got, clients)
+	}
+}
+
+// TestStepDeterministic steps two worlds with the same seed through the
+// same events and expects them to end up the same, monsters included.
+func TestStepDeterministic(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	player := &Unit{Id: "player", Skin: PlayerSkins[0], Action: UnitActionIdle, Speed: 1, AimX: 1}
+	worlds := make([]*World, 2)
+	for n := range worlds {
+		world := &World{
+			Units:      map[string]*Unit{},
+			Footprints: testFootprints(),
+			Level:      Generate("dungeon_1", 7, "", "dungeon_2"),
+			Seed:       42,
+		}
+		world.shape(player)
+		heal(player)
+		world.HandleEvent(&Event{
+			Type: Event_type_connect,
+			Data: &Event_Connect{
+				Connect: &EventConnect{Unit: proto.Clone(player).(*Unit)},
+			},
+		})
+		worlds[n] = world
+	}
+
+	for tick := uint64(1); tick <= 300; tick++ {
+		for _, world := range worlds {
+			world.HandleEvent(move(player.Id, float64(tick/50%3)-1, float64(tick/70%3)-1))
+			world.Step(tick)
+			world.Events()
+		}
+	}
+
+	a, b := worlds[0].Snapshot(), worlds[1].Snapshot()
+	if len(a.Units) < 2 {
+		t.Fatalf("no monsters were spawned: %d units", len(a.Units))
+	}
+	if !proto.Equal(a, b) {
+		t.Error("worlds stepped through the same events differ")
+	}
+}
@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"math"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// InterestRadius is how far around its own unit a player is told about
+// other units. It covers the screen with some margin.
+const InterestRadius = 480
+
+// cell is the position of a grid cell.
+type cell struct {
+	x int
+	y int
+}
+
+// Grid is a spatial hash over units for finding the ones near a point
+// without looking at all of them.
+type Grid struct {
+	size  float64
+	cells map[cell][]*Unit
+}
+
+// NewGrid indexes the units into square cells of the given size.
+func NewGrid(units map[string]*Unit, size float64) *Grid {
+	grid := &Grid{size: size, cells: map[cell][]*Unit{}}
+	for _, id := range sortedIDs(units) {
+		unit := units[id]
+		key := grid.cell(unit.X, unit.Y)
+		grid.cells[key] = append(grid.cells[key], unit)
+	}
+
+	return grid
+}
+
+func (grid *Grid) cell(x, y float64) cell {
+	return cell{
+		x: int(math.Floor(x / grid.size)),
+		y: int(math.Floor(y / grid.size)),
+	}
+}
+
+// Near returns the units within radius of the point.
+func (grid *Grid) Near(x, y, radius float64) []*Unit {
+	var units []*Unit
+	from := grid.cell(x-radius, y-radius)
+	to := grid.cell(x+radius, y+radius)
+	for i := from.x; i <= to.x; i++ {
+		for j := from.y; j <= to.y; j++ {
+			for _, unit := range grid.cells[cell{x: i, y: j}] {
+				dx, dy := unit.X-x, unit.Y-y
+				if dx*dx+dy*dy <= radius*radius {
+					units = append(units, unit)
+				}
+			}
+		}
+	}
+
+	return units
+}
+
+// Interest returns the part of the snapshot the owner of the unit with the
+// given id is allowed to see. The grid must index the same snapshot. Dead
+// units are only seen by their owner, and so are inventories.
+func Interest(snapshot *EventSnapshot, grid *Grid, id string) *EventSnapshot {
+	view := &EventSnapshot{
+		Tick:    snapshot.Tick,
+		Units:   map[string]*Unit{},
+		Items:   map[string]*Item{},
+		Objects: map[string]*Object{},
+	}
+	me, ok := snapshot.Units[id]
+	if !ok {
+		return view
+	}
+
+	for _, unit := range grid.Near(me.X, me.Y, InterestRadius) {
+		if unit.Id != id && dead(unit) {
+			continue
+		}
+		if unit.Id != id && len(unit.Inventory) > 0 {
+			unit = proto.Clone(unit).(*Unit)
+			unit.Inventory = nil
+		}
+		view.Units[unit.Id] = unit
+	}
+
+	for itemID, item := range snapshot.Items {
+		if math.Hypot(item.X-me.X, item.Y-me.Y) <= InterestRadius {
+			view.Items[itemID] = item
+		}
+	}
+	for objectID, object := range snapshot.Objects {
+		if math.Hypot(object.X-me.X, object.Y-me.Y) <= InterestRadius {
+			view.Objects[objectID] = object
+		}
+	}
+
+	return view
+}
@@ -0,0 +1,209 @@
+package internal
+
+import (
+	"image"
+	"math"
+)
+
+// SkinHealth is the health units of a skin start with, in half hearts.
+// Skins that are not listed get DefaultHealth.
+var SkinHealth = map[string]int32{
+	"big_demon":  10,
+	"big_zombie": 8,
+	"elf_f":      6,
+
+	"goblin":      3,
+	"imp":         2,
+	"skelet":      4,
+	"orc_warrior": 6,
+	"chort":       5,
+	"necromancer": 4,
+	"ogre":        12,
+	"mimic":       8,
+}
+
+// DefaultHealth is the health of skins missing from SkinHealth.
+const DefaultHealth = 6
+
+// RespawnTicks is how long a dead player waits before coming back.
+const RespawnTicks = 3 * TickRate
+
+// spawnCandidates is how many places are tried when looking for a safe one
+// to spawn at.
+const spawnCandidates = 16
+
+// dead reports whether the unit is waiting to respawn.
+func dead(unit *Unit) bool {
+	return unit.Respawn > 0
+}
+
+// heal gives the unit the full health of its skin.
+func heal(unit *Unit) {
+	unit.MaxHealth = DefaultHealth
+	if health, ok := SkinHealth[unit.Skin]; ok {
+		unit.MaxHealth = health
+	}
+	unit.Health = unit.MaxHealth
+}
+
+// damage takes health from the unit, killing it when none is left. The
+// source is the id of whoever dealt it.
+func (world *World) damage(unit *Unit, amount int32, source string) {
+	if dead(unit) || unit.Shield > 0 || amount <= 0 {
+		return
+	}
+
+	unit.Health -= amount
+	if unit.Health <= 0 {
+		world.kill(unit, source)
+	}
+}
+
+// kill takes the unit out of the game until it respawns and tells every
+// player about it. Monsters drop their loot.
+func (world *World) kill(unit *Unit, killer string) {
+	unit.Health = 0
+	unit.Respawn = RespawnTicks
+	unit.Swing, unit.Stun = 0, 0
+	unit.PushX, unit.PushY = 0, 0
+	unit.Boost, unit.Shield = 0, 0
+	unit.Action = UnitActionDead
+
+	if world.npcs[unit.Id] != nil {
+		x, y := world.center(unit)
+		world.drop(rollLoot(world.random(), MonsterLoot), x, y)
+	}
+
+	world.outbox = append(world.outbox, &Event{
+		Type: Event_type_death,
+		Data: &Event_Death{
+			Death: &EventDeath{PlayerId: unit.Id, KillerId: killer},
+		},
+	})
+}
+
+// respawn brings a dead unit back with full health at a safe place. Dead
+// monsters are gone for good; the spawn table brings new ones.
+func (world *World) respawn(unit *Unit) {
+	if world.npcs[unit.Id] != nil {
+		world.remove(unit.Id)
+		return
+	}
+
+	heal(unit)
+	unit.Respawn = 0
+	unit.Cooldown = 0
+	world.spawn(unit)
+	unit.Action = action(unit)
+}
+
+// spawn moves the unit to the safest of a few random spawn points: the one
+// farthest from every other living unit where it does not stand in a wall
+// or over a pit. The spawn points of the level are used, or any open tile
+// if it has none.
+func (world *World) spawn(unit *Unit) {
+	if world.Level == nil {
+		return
+	}
+
+	var points [][2]float64
+	for _, point := range world.Level.Spawns {
+		points = append(points, [2]float64{float64(point.X), float64(point.Y)})
+	}
+	if len(points) == 0 {
+		for j := 0; j < world.Level.Height(); j++ {
+			for i := 0; i < world.Level.Width(); i++ {
+				if !world.impassable(i, j) {
+					points = append(points, [2]float64{(float64(i) + 0.5) * TileSize, (float64(j) + 0.5) * TileSize})
+				}
+			}
+		}
+	}
+	if len(points) == 0 {
+		return
+	}
+
+	x, y := unit.X, unit.Y
+	best := -1.0
+	for n := 0; n < spawnCandidates; n++ {
+		point := points[world.random().Intn(len(points))]
+		world.place(unit, point[0], point[1])
+		if !world.fits(unit) {
+			continue
+		}
+
+		safety := math.Inf(1)
+		for _, other := range world.Units {
+			if other == unit || dead(other) {
+				continue
+			}
+			ox, oy := world.center(other)
+			safety = math.Min(safety, math.Hypot(ox-point[0], oy-point[1]))
+		}
+		if safety > best {
+			best = safety
+			x, y = unit.X, unit.Y
+		}
+	}
+	unit.X, unit.Y = x, y
+}
+
+// place moves the unit so that the middle of its collider is at x, y.
+func (world *World) place(unit *Unit, x, y float64) {
+	cx, cy := world.center(unit)
+	unit.X += x - cx
+	unit.Y += y - cy
+}
+
+// maxNudge is how many tiles away from where it should go a unit may be
+// put when it does not fit there.
+const maxNudge = 3
+
+// nudge places the unit at x, y, or at the nearest middle of a tile around
+// it where the unit neither stands in a wall nor over a pit. It reports
+// false when there is no such place within maxNudge tiles.
+func (world *World) nudge(unit *Unit, x, y float64) bool {
+	world.place(unit, x, y)
+	if world.Level == nil || world.fits(unit) {
+		return true
+	}
+
+	i := int(math.Floor(x / TileSize))
+	j := int(math.Floor(y / TileSize))
+	for r := 1; r <= maxNudge; r++ {
+		best, found := math.Inf(1), image.Point{}
+		for dj := -r; dj <= r; dj++ {
+			for di := -r; di <= r; di++ {
+				if di != -r && di != r && dj != -r && dj != r {
+					continue
+				}
+				px, py := (float64(i+di)+0.5)*TileSize, (float64(j+dj)+0.5)*TileSize
+				world.place(unit, px, py)
+				if d := math.Hypot(px-x, py-y); world.fits(unit) && d < best {
+					best, found = d, image.Pt(di, dj)
+				}
+			}
+		}
+		if !math.IsInf(best, 1) {
+			world.place(unit, (float64(i+found.X)+0.5)*TileSize, (float64(j+found.Y)+0.5)*TileSize)
+			return true
+		}
+	}
+
+	world.place(unit, x, y)
+	return false
+}
+
+// fits reports whether the unit stands clear of walls and pits.
+func (world *World) fits(unit *Unit) bool {
+	return !world.blocked(world.footprint(unit)) && !world.pits[world.tile(unit)]
+}
+
+// Events returns what happened in the world since the last call that every
+// player should hear about, like deaths. The server broadcasts them.
+func (world *World) Events() []*Event {
+	events := world.outbox
+	world.outbox = nil
+
+	return events
+}
@@ -0,0 +1,78 @@
+package internal
+
+import "time"
+
+// DefaultInterpolationDelay is used when World.InterpolationDelay is zero.
+// It should cover a couple of snapshot intervals plus the usual jitter.
+const DefaultInterpolationDelay = 100 * time.Millisecond
+
+// maxSamples bounds the snapshot buffer kept for each remote unit.
+const maxSamples = 32
+
+const tickDuration = time.Second / TickRate
+
+// sample is the position of a remote unit at a server tick.
+type sample struct {
+	tick uint64
+	x    float64
+	y    float64
+}
+
+// syncClock estimates when the server was at tick 0 in local time. Late
+// packets only nudge the estimate, so a single delayed snapshot does not
+// make remote units jump.
+func (world *World) syncClock(tick uint64, now time.Time) {
+	estimate := now.Add(-time.Duration(tick) * tickDuration)
+	if world.clock.IsZero() {
+		world.clock = estimate
+		return
+	}
+	world.clock = world.clock.Add(estimate.Sub(world.clock) / 10)
+}
+
+// remember appends the state of a remote unit to its snapshot buffer.
+func (world *World) remember(tick uint64, unit *Unit) {
+	if world.samples == nil {
+		world.samples = map[string][]sample{}
+	}
+
+	samples := world.samples[unit.Id]
+	if n := len(samples); n > 0 && samples[n-1].tick >= tick {
+		return
+	}
+	samples = append(samples, sample{tick: tick, x: unit.X, y: unit.Y})
+	if len(samples) > maxSamples {
+		samples = samples[len(samples)-maxSamples:]
+	}
+	world.samples[unit.Id] = samples
+}
+
+// Position returns where the unit should be drawn at the given time. The
+// local player is predicted and drawn where it is; remote units are drawn
+// in the past, between the two server states around the render time.
+func (world *World) Position(unit *Unit, now time.Time) (float64, float64) {
+	samples := world.samples[unit.Id]
+	if !world.Replica || unit.Id == world.MyID || len(samples) == 0 {
+		return unit.X, unit.Y
+	}
+
+	delay := world.InterpolationDelay
+	if delay == 0 {
+		delay = DefaultInterpolationDelay
+	}
+	at := float64(now.Sub(world.clock)-delay) / float64(tickDuration)
+
+	if at <= float64(samples[0].tick) {
+		return samples[0].x, samples[0].y
+	}
+	for i := 1; i < len(samples); i++ {
+		from, to := samples[i-1], samples[i]
+		if at < float64(to.tick) {
+			t := (at - float64(from.tick)) / float64(to.tick-from.tick)
+			return from.x + (to.x-from.x)*t, from.y + (to.y-from.y)*t
+		}
+	}
+
+	last := samples[len(samples)-1]
+	return last.x, last.y
+}
@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// ItemKind describes what an item does when it is used. Items without any
+// effect, like coins, are only collected. Every kind has a sprite of the
+// same name.
+type ItemKind struct {
+	// Heal restores health and Grow raises the maximum health until the
+	// next respawn, both in half hearts.
+	Heal int32
+	Grow int32
+	// Boost makes the unit faster and Shield keeps it from taking damage,
+	// for as many ticks.
+	Boost  uint32
+	Shield uint32
+	// Stack is how many of the kind fit in a slot.
+	Stack int32
+}
+
+// consumable reports whether items of the kind have an effect when used.
+func (kind ItemKind) consumable() bool {
+	return kind.Heal != 0 || kind.Grow != 0 || kind.Boost != 0 || kind.Shield != 0
+}
+
+// ItemKinds are the items that can lie in the world.
+var ItemKinds = map[string]ItemKind{
+	"coin":             {Stack: 999},
+	"flask_red":        {Heal: 2, Stack: 9},
+	"flask_big_red":    {Heal: 6, Stack: 9},
+	"flask_blue":       {Boost: 5 * TickRate, Stack: 9},
+	"flask_big_blue":   {Boost: 12 * TickRate, Stack: 9},
+	"flask_green":      {Grow: 1, Heal: 1, Stack: 9},
+	"flask_big_green":  {Grow: 2, Heal: 2, Stack: 9},
+	"flask_yellow":     {Shield: 3 * TickRate, Stack: 9},
+	"flask_big_yellow": {Shield: 8 * TickRate, Stack: 9},
+}
+
+// MonsterLoot is what monsters may drop when they die, with weights. The
+// empty kind drops nothing.
+var MonsterLoot = []Loot{
+	{Kind: "", Weight: 8},
+	{Kind: "coin", Weight: 6},
+	{Kind: "flask_red", Weight: 3},
+	{Kind: "flask_blue", Weight: 1},
+	{Kind: "flask_green", Weight: 1},
+	{Kind: "flask_yellow", Weight: 1},
+}
+
+// Loot is an entry of a loot table.
+type Loot struct {
+	Kind   string
+	Weight int
+}
+
+// InventorySize is the number of slots of an inventory.
+const InventorySize = 5
+
+// itemRadius is how close a unit has to get to an item to pick it up.
+const itemRadius = 6
+
+// boostFactor multiplies the speed of boosted units.
+const boostFactor = 1.6
+
+// rollLoot picks a kind from a loot table at random.
+func rollLoot(rnd *rand.Rand, table []Loot) string {
+	total := 0
+	for _, loot := range table {
+		total += loot.Weight
+	}
+	if total <= 0 {
+		return ""
+	}
+
+	n := rnd.Intn(total)
+	for _, loot := range table {
+		if n < loot.Weight {
+			return loot.Kind
+		}
+		n -= loot.Weight
+	}
+
+	return ""
+}
+
+// drop puts an item of the kind into the world at x, y.
+func (world *World) drop(kind string, x, y float64) {
+	if _, ok := ItemKinds[kind]; !ok {
+		return
+	}
+	if world.Items == nil {
+		world.Items = map[string]*Item{}
+	}
+
+	id := world.newID("item")
+	world.Items[id] = &Item{Id: id, Kind: kind, X: x, Y: y}
+}
+
+// collect lets living players pick up the items they stand on. Items that
+// do not fit in the inventory stay where they are.
+func (world *World) collect() {
+	for _, id := range sortedIDs(world.Units) {
+		unit := world.Units[id]
+		if world.npcs[id] != nil || dead(unit) {
+			continue
+		}
+
+		x, y := world.center(unit)
+		for _, itemID := range sortedItemIDs(world.Items) {
+			item := world.Items[itemID]
+			if math.Hypot(item.X-x, item.Y-y) > unit.Radius+itemRadius {
+				continue
+			}
+			if store(unit, item.Kind) {
+				delete(world.Items, itemID)
+			}
+		}
+	}
+}
+
+// sortedItemIDs returns the ids of the items in a stable order.
+func sortedItemIDs(items map[string]*Item) []string {
+	ids := make([]string, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// store puts one item of the kind into the inventory of the unit, on a
+// slot of the same kind if there is room or on a new one.
+func store(unit *Unit, kind string) bool {
+	for _, slot := range unit.Inventory {
+		if slot.Item == kind && slot.Count < ItemKinds[kind].Stack {
+			slot.Count++
+			return true
+		}
+	}
+	if len(unit.Inventory) >= InventorySize {
+		return false
+	}
+
+	unit.Inventory = append(unit.Inventory, &Slot{Item: kind, Count: 1})
+	return true
+}
+
+// use applies one item of the slot to its owner. Items without an effect
+// stay in the inventory.
+func (world *World) use(unit *Unit, index int) {
+	if dead(unit) || index < 0 || index >= len(unit.Inventory) {
+		return
+	}
+	slot := unit.Inventory[index]
+	kind := ItemKinds[slot.Item]
+	if !kind.consumable() {
+		return
+	}
+
+	unit.MaxHealth += kind.Grow
+	unit.Health = min(unit.Health+kind.Heal, unit.MaxHealth)
+	unit.Boost = max(unit.Boost, kind.Boost)
+	unit.Shield = max(unit.Shield, kind.Shield)
+
+	slot.Count--
+	if slot.Count <= 0 {
+		unit.Inventory = append(unit.Inventory[:index], unit.Inventory[index+1:]...)
+	}
+}
+
+// speed returns how far the unit walks in a tick at full input.
+func speed(unit *Unit) float64 {
+	if unit.Boost > 0 {
+		return unit.Speed * boostFactor
+	}
+	return unit.Speed
+}
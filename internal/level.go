@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"image"
+	"log"
+	"strings"
+)
+
+// TileSize is the width and height of a level tile in pixels.
+const TileSize = 16
+
+// Level is the static map a world is played on.
+type Level struct {
+	// Name tells levels apart; ladders lead to levels by name.
+	Name string
+
+	// Seed is what the level was generated from, or zero when it was
+	// loaded from a file.
+	Seed int64
+
+	// Layers hold the sprite name of every tile, row by row, from the
+	// bottom layer up. Empty names leave a tile of a layer blank.
+	Layers [][][]string
+
+	// Solids tells which sprites block units. Sprites it does not know are
+	// solid when they are walls or holes.
+	Solids map[string]bool
+
+	// Spawns are the points in pixels where units may appear. Any open
+	// tile is used when there are none.
+	Spawns []image.Point
+
+	// SpawnTable tells which monsters roam the level.
+	SpawnTable SpawnTable
+
+	// Objects are placed in the world when it starts, centered on their
+	// position in pixels.
+	Objects []*Object
+}
+
+// Width returns the width of the level in tiles.
+func (level *Level) Width() int {
+	if level == nil || len(level.Layers) == 0 || len(level.Layers[0]) == 0 {
+		return 0
+	}
+	return len(level.Layers[0][0])
+}
+
+// Height returns the height of the level in tiles.
+func (level *Level) Height() int {
+	if level == nil || len(level.Layers) == 0 {
+		return 0
+	}
+	return len(level.Layers[0])
+}
+
+// Solid reports whether units cannot stand on the tile in column i and
+// row j, because a sprite on any of its layers is solid. Tiles without
+// any sprite and everything outside the map are solid too.
+func (level *Level) Solid(i, j int) bool {
+	if i < 0 || j < 0 || i >= level.Width() || j >= level.Height() {
+		return true
+	}
+
+	empty := true
+	for _, layer := range level.Layers {
+		if level.solidSprite(layer[j][i]) {
+			return true
+		}
+		empty = empty && layer[j][i] == ""
+	}
+	return empty
+}
+
+func (level *Level) solidSprite(sprite string) bool {
+	if solid, ok := level.Solids[sprite]; ok {
+		return solid
+	}
+	return strings.HasPrefix(sprite, "wall_") || sprite == "hole"
+}
+
+// enter makes a replica play on the level the server says it is on,
+// building it unless it already is.
+func (world *World) enter(name string, seed int64) {
+	if world.Level != nil && world.Level.Name == name && world.Level.Seed == seed {
+		return
+	}
+
+	level, err := OpenLevel(name, seed)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	world.Level = level
+	world.Objects = nil
+	world.Items = nil
+	world.fence()
+}
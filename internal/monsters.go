@@ -0,0 +1,319 @@
+package internal
+
+import (
+	"image"
+	"math"
+	"math/rand"
+)
+
+// Monster describes how monsters of a skin behave. Their health and mass
+// come from SkinHealth and SkinMass like for any other unit.
+type Monster struct {
+	Speed  float64
+	Weapon string
+	// Sight is how far away the monster notices players.
+	Sight float64
+	// Flee is the share of its health below which the monster runs away
+	// from players instead of fighting them. Zero means it never does.
+	Flee float64
+}
+
+// Monsters are the skins the server can spawn as monsters. Mimics only come
+// out of chests.
+var Monsters = map[string]Monster{
+	"goblin":      {Speed: 0.9, Weapon: "knife", Sight: 120, Flee: 0.3},
+	"imp":         {Speed: 1.1, Weapon: "knife", Sight: 100, Flee: 0.5},
+	"skelet":      {Speed: 0.7, Weapon: "rusty_sword", Sight: 140},
+	"orc_warrior": {Speed: 0.7, Weapon: "cleaver", Sight: 140},
+	"chort":       {Speed: 0.9, Weapon: "baton_with_spikes", Sight: 160},
+	"necromancer": {Speed: 0.6, Weapon: "red_magic_staff", Sight: 180, Flee: 0.4},
+	"ogre":        {Speed: 0.5, Weapon: "mace", Sight: 120},
+	"mimic":       {Speed: 0.8, Weapon: "bite", Sight: 160},
+}
+
+// SpawnTable tells which monsters a level is populated with.
+type SpawnTable struct {
+	// Count is how many monsters the server keeps alive.
+	Count   int
+	Entries []SpawnEntry
+}
+
+// SpawnEntry is a kind of monster in a spawn table. Entries are rolled in
+// proportion to their weight.
+type SpawnEntry struct {
+	Skin   string
+	Weight int
+	// Patrol makes the monster walk a round around its spawn point
+	// instead of wandering.
+	Patrol bool
+}
+
+// roll picks an entry of the table at random.
+func (table *SpawnTable) roll(rnd *rand.Rand) (SpawnEntry, bool) {
+	total := 0
+	for _, entry := range table.Entries {
+		total += entry.Weight
+	}
+	if total <= 0 {
+		return SpawnEntry{}, false
+	}
+
+	n := rnd.Intn(total)
+	for _, entry := range table.Entries {
+		if n < entry.Weight {
+			return entry, true
+		}
+		n -= entry.Weight
+	}
+
+	return SpawnEntry{}, false
+}
+
+// Command is what an NPC wants to do for a tick: the same as the input of a
+// player.
+type Command struct {
+	X      float64
+	Y      float64
+	Attack bool
+}
+
+// Behavior decides what an NPC does this tick. It returns false when it
+// does not apply, so the next behavior of the NPC gets to decide.
+type Behavior func(world *World, npc *NPC, unit *Unit) (Command, bool)
+
+// NPC is the mind of a unit controlled by the server. Its behaviors are
+// tried in order every tick and the first that applies is followed.
+type NPC struct {
+	ID        string
+	Behaviors []Behavior
+
+	// Home is where the NPC spawned, in pixels.
+	Home image.Point
+
+	// State kept by the behaviors between ticks.
+	waypoint int
+	wander   Command
+	wait     int
+
+	// The path the NPC follows and the tile it leads to.
+	path []image.Point
+	goal image.Point
+}
+
+// AddMonster puts a monster of the given skin on the level with its collider
+// centered at x, y. It must only be called from the goroutine that runs
+// Step.
+func (world *World) AddMonster(skin string, x, y float64, behaviors ...Behavior) *Unit {
+	monster := Monsters[skin]
+	unit := &Unit{
+		Id:     world.newID("monster"),
+		Frame:  int32(world.random().Intn(4)),
+		Skin:   skin,
+		Action: UnitActionIdle,
+		Speed:  monster.Speed,
+		Weapon: monster.Weapon,
+		AimX:   1,
+	}
+	world.shape(unit)
+	heal(unit)
+	world.place(unit, x, y)
+
+	if world.npcs == nil {
+		world.npcs = map[string]*NPC{}
+	}
+	world.npcs[unit.Id] = &NPC{
+		ID:        unit.Id,
+		Behaviors: behaviors,
+		Home:      image.Pt(int(x), int(y)),
+	}
+	world.Units[unit.Id] = unit
+
+	return unit
+}
+
+// populate tops the monsters of the level up to the count of its spawn
+// table, one per tick.
+func (world *World) populate() {
+	if world.Level == nil || len(world.npcs) >= world.Level.SpawnTable.Count {
+		return
+	}
+	entry, ok := world.Level.SpawnTable.roll(world.random())
+	if !ok {
+		return
+	}
+	monster, ok := Monsters[entry.Skin]
+	if !ok {
+		return
+	}
+
+	unit := world.AddMonster(entry.Skin, 0, 0)
+	world.spawn(unit)
+	x, y := world.center(unit)
+	npc := world.npcs[unit.Id]
+	npc.Home = image.Pt(int(x), int(y))
+
+	npc.Behaviors = []Behavior{Chase(monster.Sight)}
+	if monster.Flee > 0 {
+		npc.Behaviors = []Behavior{Flee(monster.Flee, monster.Sight), Chase(monster.Sight)}
+	}
+	if entry.Patrol {
+		npc.Behaviors = append(npc.Behaviors, Patrol(world.round(npc.Home, 3)))
+	} else {
+		npc.Behaviors = append(npc.Behaviors, Wander(3*TileSize))
+	}
+}
+
+// round returns the corners of a square around home, reach tiles away,
+// leaving out the ones that are not open.
+func (world *World) round(home image.Point, reach int) []image.Point {
+	var route []image.Point
+	for _, d := range []image.Point{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}} {
+		point := home.Add(d.Mul(reach * TileSize))
+		if !world.impassable(point.X/TileSize, point.Y/TileSize) {
+			route = append(route, point)
+		}
+	}
+
+	return route
+}
+
+// think lets every NPC decide what to do and applies it like the input of
+// a player.
+func (world *World) think() {
+	if world.paths != nil {
+		world.paths.Refill()
+	}
+	for _, id := range sortedIDs(world.Units) {
+		npc := world.npcs[id]
+		unit := world.Units[id]
+		if npc == nil || dead(unit) {
+			continue
+		}
+
+		command := Command{}
+		for _, behavior := range npc.Behaviors {
+			if c, ok := behavior(world, npc, unit); ok {
+				command = c
+				break
+			}
+		}
+
+		unit.MoveX, unit.MoveY = normalize(command.X, command.Y)
+		unit.Action = action(unit)
+		if command.Attack {
+			world.attack(unit)
+		}
+	}
+}
+
+// remove takes a unit out of the world for good.
+func (world *World) remove(id string) {
+	delete(world.Units, id)
+	delete(world.npcs, id)
+	delete(world.samples, id)
+}
+
+// allies reports whether two units are on the same side. Monsters do not
+// hurt each other.
+func (world *World) allies(a, b *Unit) bool {
+	return world.npcs[a.Id] != nil && world.npcs[b.Id] != nil
+}
+
+// nearestPlayer returns the closest living player within reach of the
+// unit, and the way to it.
+func (world *World) nearestPlayer(unit *Unit, reach float64) (*Unit, float64, float64) {
+	x, y := world.center(unit)
+	var nearest *Unit
+	var nx, ny float64
+	for _, id := range sortedIDs(world.Units) {
+		other := world.Units[id]
+		if world.npcs[id] != nil || dead(other) {
+			continue
+		}
+
+		ox, oy := world.center(other)
+		if d := math.Hypot(ox-x, oy-y); d <= reach {
+			nearest, nx, ny, reach = other, ox-x, oy-y, d
+		}
+	}
+
+	return nearest, nx, ny
+}
+
+// Chase walks around walls towards the nearest player in sight and attacks
+// once it is within reach of the weapon.
+func Chase(sight float64) Behavior {
+	return func(world *World, npc *NPC, unit *Unit) (Command, bool) {
+		target, dx, dy := world.nearestPlayer(unit, sight)
+		if target == nil {
+			return Command{}, false
+		}
+
+		x, y := world.center(target)
+		mx, my := world.steer(npc, unit, x, y)
+		reach := Weapons[unit.Weapon].Reach + target.Radius
+		return Command{X: mx, Y: my, Attack: math.Hypot(dx, dy) <= reach}, true
+	}
+}
+
+// Flee runs away from the nearest player in sight while the health of the
+// unit is below the given share.
+func Flee(below, sight float64) Behavior {
+	return func(world *World, npc *NPC, unit *Unit) (Command, bool) {
+		if float64(unit.Health) > below*float64(unit.MaxHealth) {
+			return Command{}, false
+		}
+		target, dx, dy := world.nearestPlayer(unit, sight)
+		if target == nil {
+			return Command{}, false
+		}
+
+		return Command{X: -dx, Y: -dy}, true
+	}
+}
+
+// Patrol walks from one point of the route to the next, in pixels, and
+// starts over at the end.
+func Patrol(route []image.Point) Behavior {
+	return func(world *World, npc *NPC, unit *Unit) (Command, bool) {
+		if len(route) == 0 {
+			return Command{}, false
+		}
+
+		x, y := world.center(unit)
+		point := route[npc.waypoint%len(route)]
+		if math.Hypot(float64(point.X)-x, float64(point.Y)-y) < TileSize/2 {
+			npc.waypoint++
+		}
+
+		dx, dy := world.steer(npc, unit, float64(point.X), float64(point.Y))
+		return Command{X: dx, Y: dy}, true
+	}
+}
+
+// Wander strolls around in random directions with pauses, heading back
+// whenever it gets further than radius from home.
+func Wander(radius float64) Behavior {
+	return func(world *World, npc *NPC, unit *Unit) (Command, bool) {
+		if npc.wait > 0 {
+			npc.wait--
+			return npc.wander, true
+		}
+
+		x, y := world.center(unit)
+		dx, dy := float64(npc.Home.X)-x, float64(npc.Home.Y)-y
+		switch {
+		case math.Hypot(dx, dy) > radius:
+			dx, dy = world.steer(npc, unit, float64(npc.Home.X), float64(npc.Home.Y))
+			npc.wander = Command{X: dx, Y: dy}
+		case npc.wander != Command{}:
+			npc.wander = Command{}
+		default:
+			angle := world.random().Float64() * 2 * math.Pi
+			npc.wander = Command{X: math.Cos(angle) / 2, Y: math.Sin(angle) / 2}
+		}
+		npc.wait = TickRate/2 + world.random().Intn(TickRate*3/2)
+
+		return npc.wander, true
+	}
+}
@@ -0,0 +1,378 @@
+package internal
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Object kinds. Decorations only show the sprite named by their state.
+const (
+	ObjectChest      = "chest"
+	ObjectDoor       = "door"
+	ObjectSpikes     = "spikes"
+	ObjectPit        = "hole"
+	ObjectLadder     = "ladder"
+	ObjectDecoration = "decoration"
+)
+
+// States of a chest. A closed chest turns into one of the others when it
+// is opened, and plays the opening animation of that state.
+const (
+	ChestClosed = "closed"
+	ChestFull   = "full"
+	ChestEmpty  = "empty"
+	ChestMimic  = "mimic"
+)
+
+// States of a door. Closed doors block the tiles they stand on.
+const (
+	DoorOpen   = "open"
+	DoorClosed = "closed"
+)
+
+// States of spikes. They come up for SpikesUpTicks every SpikesDownTicks,
+// hurting everyone standing on them as they rise. The timer they are
+// placed with sets them apart.
+const (
+	SpikesDown = "down"
+	SpikesUp   = "up"
+)
+
+const SpikesDownTicks = 90
+const SpikesUpTicks = 60
+
+// SpikesDamage is dealt by rising spikes and PitDamage by falling into a
+// pit.
+const SpikesDamage = 1
+const PitDamage = 2
+
+// ChestOpenTicks is how long the opening animation of a chest lasts.
+const ChestOpenTicks = 24
+
+// MimicChance is the share of chests that turn out to be mimics, and
+// EmptyChance the share of the others that hold nothing.
+const MimicChance = 0.15
+const EmptyChance = 0.2
+
+// interactReach is how far from the middle of its collider a unit can
+// reach objects.
+const interactReach = 20
+
+// ChestLoot is what full chests are filled with. Every chest rolls it a
+// few times; the empty kind adds nothing.
+var ChestLoot = []Loot{
+	{Kind: "", Weight: 4},
+	{Kind: "coin", Weight: 8},
+	{Kind: "flask_red", Weight: 3},
+	{Kind: "flask_big_red", Weight: 1},
+	{Kind: "flask_blue", Weight: 2},
+	{Kind: "flask_big_blue", Weight: 1},
+	{Kind: "flask_green", Weight: 2},
+	{Kind: "flask_big_green", Weight: 1},
+	{Kind: "flask_yellow", Weight: 2},
+	{Kind: "flask_big_yellow", Weight: 1},
+}
+
+// chestRolls is how many times a chest rolls its loot.
+const chestRolls = 3
+
+// ObjectBox returns the area an object covers in pixels, centered on its
+// position. Objects without a size cover a tile.
+func ObjectBox(object *Object) (x0, y0, x1, y1 float64) {
+	width, height := object.Width, object.Height
+	if width == 0 {
+		width = TileSize
+	}
+	if height == 0 {
+		height = TileSize
+	}
+
+	return object.X - width/2, object.Y - height/2, object.X + width/2, object.Y + height/2
+}
+
+// tiles returns the tiles whose middle the object covers.
+func tiles(object *Object) []image.Point {
+	x0, y0, x1, y1 := ObjectBox(object)
+	var points []image.Point
+	for i := int(math.Floor(x0 / TileSize)); float64(i)*TileSize < x1; i++ {
+		for j := int(math.Floor(y0 / TileSize)); float64(j)*TileSize < y1; j++ {
+			x, y := (float64(i)+0.5)*TileSize, (float64(j)+0.5)*TileSize
+			if x >= x0 && x < x1 && y >= y0 && y < y1 {
+				points = append(points, image.Pt(i, j))
+			}
+		}
+	}
+
+	return points
+}
+
+// furnish puts the objects of the level into the world the first time it
+// runs.
+func (world *World) furnish() {
+	if world.Objects != nil || world.Level == nil {
+		return
+	}
+
+	world.Objects = make(map[string]*Object, len(world.Level.Objects))
+	for _, object := range world.Level.Objects {
+		object = proto.Clone(object).(*Object)
+		if object.Kind == ObjectSpikes && object.Timer == 0 {
+			object.Timer = SpikesDownTicks
+		}
+		world.Objects[object.Id] = object
+	}
+	world.fence()
+}
+
+// fence indexes the tiles that closed doors block and the ones pits are
+// on. It must be called whenever those objects change.
+func (world *World) fence() {
+	world.barriers = map[image.Point]bool{}
+	world.pits = map[image.Point]bool{}
+	for _, object := range world.Objects {
+		switch {
+		case object.Kind == ObjectDoor && object.State == DoorClosed:
+			for _, tile := range tiles(object) {
+				world.barriers[tile] = true
+			}
+		case object.Kind == ObjectPit:
+			for _, tile := range tiles(object) {
+				world.pits[tile] = true
+			}
+		}
+	}
+	if world.paths != nil {
+		world.paths.Invalidate()
+	}
+}
+
+// solid reports whether units cannot walk on the tile: a solid tile of
+// the level or one blocked by a closed door.
+func (world *World) solid(i, j int) bool {
+	return world.Level.Solid(i, j) || world.barriers[image.Pt(i, j)]
+}
+
+// impassable reports whether NPCs should keep off the tile. They avoid
+// pits on top of what blocks everyone.
+func (world *World) impassable(i, j int) bool {
+	return world.solid(i, j) || world.pits[image.Pt(i, j)]
+}
+
+// interact makes the unit use the nearest object within its reach.
+func (world *World) interact(unit *Unit) {
+	if dead(unit) {
+		return
+	}
+
+	x, y := world.center(unit)
+	var nearest *Object
+	reach := float64(interactReach + TileSize/2)
+	for _, id := range sortedObjectIDs(world.Objects) {
+		object := world.Objects[id]
+		if d := math.Hypot(object.X-x, object.Y-y); d <= reach {
+			nearest, reach = object, d
+		}
+	}
+	if nearest == nil {
+		return
+	}
+
+	switch nearest.Kind {
+	case ObjectChest:
+		world.open(nearest)
+	case ObjectDoor:
+		world.swing(nearest)
+	case ObjectLadder:
+		world.leave(unit, nearest.Target)
+	}
+}
+
+// swing opens a closed door and closes an open one, unless someone stands
+// in the doorway.
+func (world *World) swing(door *Object) {
+	if door.State == DoorOpen {
+		x0, y0, x1, y1 := ObjectBox(door)
+		for _, unit := range world.Units {
+			b := world.footprint(unit)
+			if !dead(unit) && b.x0 < x1 && b.x1 > x0 && b.y0 < y1 && b.y1 > y0 {
+				return
+			}
+		}
+		door.State = DoorClosed
+	} else {
+		door.State = DoorOpen
+	}
+	world.fence()
+}
+
+// open starts opening a closed chest. What is inside is decided right
+// away, so the right animation plays, and comes out once it is done.
+func (world *World) open(chest *Object) {
+	if chest.State != ChestClosed {
+		return
+	}
+
+	chest.Timer = ChestOpenTicks
+	switch roll := world.random().Float64(); {
+	case roll < MimicChance:
+		chest.State = ChestMimic
+	case roll < MimicChance+(1-MimicChance)*EmptyChance:
+		chest.State = ChestEmpty
+	default:
+		chest.State = ChestFull
+	}
+}
+
+// operate advances the animations and cycles of the objects by a single
+// tick and does what they do at the end, then lets units fall into pits.
+func (world *World) operate() {
+	defer world.trap()
+
+	for _, id := range sortedObjectIDs(world.Objects) {
+		object := world.Objects[id]
+		if object.Timer == 0 {
+			continue
+		}
+		object.Timer--
+		if object.Timer > 0 {
+			continue
+		}
+
+		switch {
+		case object.Kind == ObjectSpikes && object.State == SpikesUp:
+			object.State = SpikesDown
+			object.Timer = SpikesDownTicks
+
+		case object.Kind == ObjectSpikes:
+			object.State = SpikesUp
+			object.Timer = SpikesUpTicks
+			world.prick(object)
+
+		case object.Kind == ObjectChest && object.State == ChestFull:
+			world.spill(object)
+			object.State = ChestEmpty
+
+		case object.Kind == ObjectChest && object.State == ChestMimic:
+			delete(world.Objects, id)
+			monster := Monsters["mimic"]
+			world.AddMonster("mimic", object.X, object.Y, Chase(monster.Sight), Wander(2*TileSize))
+		}
+	}
+}
+
+// prick hurts every living unit standing on the spikes.
+func (world *World) prick(spikes *Object) {
+	x0, y0, x1, y1 := ObjectBox(spikes)
+	for _, id := range sortedIDs(world.Units) {
+		unit := world.Units[id]
+		b := world.footprint(unit)
+		if b.x0 < x1 && b.x1 > x0 && b.y0 < y1 && b.y1 > y0 {
+			world.damage(unit, SpikesDamage, spikes.Id)
+		}
+	}
+}
+
+// trap makes the units whose feet are over a pit fall in. Players get hurt
+// and climb out at a safe place; monsters are gone.
+func (world *World) trap() {
+	for _, id := range sortedIDs(world.Units) {
+		unit := world.Units[id]
+		if dead(unit) || !world.pits[world.tile(unit)] {
+			continue
+		}
+
+		if world.npcs[id] != nil {
+			world.kill(unit, "")
+			continue
+		}
+		world.damage(unit, PitDamage, "")
+		if !dead(unit) {
+			world.spawn(unit)
+		}
+	}
+}
+
+// Departure is a unit that left the world for another level.
+type Departure struct {
+	Unit  *Unit
+	Level string
+}
+
+// leave takes a player out of the world towards another level.
+func (world *World) leave(unit *Unit, level string) {
+	if world.npcs[unit.Id] != nil {
+		return
+	}
+
+	world.remove(unit.Id)
+	world.departures = append(world.departures, Departure{Unit: unit, Level: level})
+}
+
+// Departures returns the units that left the world since the last call.
+// The server brings them into the world of their level with Arrive.
+func (world *World) Departures() []Departure {
+	departures := world.departures
+	world.departures = nil
+
+	return departures
+}
+
+// Arrive brings a unit from the level with the given name into the world
+// on the next Step, at the ladder that leads back there. It is safe to call
+// from any goroutine.
+func (world *World) Arrive(unit *Unit, from string) {
+	world.HandleEvent(&Event{
+		Type: Event_type_connect,
+		Data: &Event_Connect{
+			Connect: &EventConnect{Unit: unit, From: from},
+		},
+	})
+}
+
+// arrive places a unit coming from another level at the ladder leading
+// back there, or next to it when the unit does not fit on it. New players
+// and those without such a ladder or room around it are put at a safe
+// place.
+func (world *World) arrive(unit *Unit, from string) {
+	for _, id := range sortedObjectIDs(world.Objects) {
+		object := world.Objects[id]
+		if from != "" && object.Kind == ObjectLadder && object.Target == from {
+			if world.nudge(unit, object.X, object.Y) {
+				return
+			}
+			break
+		}
+	}
+	world.spawn(unit)
+}
+
+// sortedObjectIDs returns the ids of the objects in a stable order.
+func sortedObjectIDs(objects map[string]*Object) []string {
+	ids := make([]string, 0, len(objects))
+	for id := range objects {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// spill drops the loot of a full chest on the floor in front of it. There
+// is always at least a coin.
+func (world *World) spill(chest *Object) {
+	n := 0
+	for i := 0; i < chestRolls; i++ {
+		kind := rollLoot(world.random(), ChestLoot)
+		if kind == "" {
+			continue
+		}
+		world.drop(kind, chest.X+float64(n*8-8), chest.Y+TileSize)
+		n++
+	}
+	if n == 0 {
+		world.drop("coin", chest.X, chest.Y+TileSize)
+	}
+}
@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+// TestArriveFits checks that big units coming down a ladder of a generated
+// floor never end up in a wall.
+func TestArriveFits(t *testing.T) {
+	footprints := testFootprints()
+	footprints["big_demon"] = image.Rect(4, 27, 28, 36)
+
+	for seed := int64(1); seed < 200; seed++ {
+		world := &World{
+			Units:      map[string]*Unit{},
+			Footprints: footprints,
+			Level:      Generate("dungeon_2", seed, "dungeon_1", "dungeon_3"),
+		}
+		world.furnish()
+
+		for _, from := range []string{"dungeon_1", "dungeon_3"} {
+			unit := &Unit{Id: "player", Skin: "big_demon"}
+			world.arrive(unit, from)
+			if ladder := nearestLadder(world, unit); ladder == nil || ladder.Target != from {
+				t.Errorf("seed %d: arrived from %s away from its ladder", seed, from)
+			}
+			if world.blocked(world.footprint(unit)) {
+				t.Errorf("seed %d: arrived from %s in a wall at %v, %v", seed, from, unit.X, unit.Y)
+			}
+		}
+	}
+}
+
+func nearestLadder(world *World, unit *Unit) *Object {
+	x, y := world.center(unit)
+	var nearest *Object
+	reach := float64(maxNudge+1) * TileSize
+	for _, object := range world.Objects {
+		if d := math.Hypot(object.X-x, object.Y-y); object.Kind == ObjectLadder && d <= reach {
+			nearest, reach = object, d
+		}
+	}
+	return nearest
+}
@@ -0,0 +1,289 @@
+package internal
+
+import (
+	"container/heap"
+	"image"
+	"math"
+)
+
+// PathBudget is how many tiles the pathfinder may explore in a tick, for
+// all NPCs together. Searches that would go over it carry on the next tick.
+const PathBudget = 4096
+
+// searchLimit is how many tiles a single search may explore, more than a
+// generated floor has. Goals that are not found by then are given up on
+// for failedSearchTicks, so one far or unreachable goal cannot eat the
+// budget of every tick.
+const searchLimit = PathBudget
+const failedSearchTicks = TickRate
+
+// maxCachedPaths bounds the paths the pathfinder remembers.
+const maxCachedPaths = 1024
+
+// route is a pair of tiles a path was searched between.
+type route struct {
+	from image.Point
+	to   image.Point
+}
+
+// Pathfinder finds ways between tiles of a level around solid tiles with
+// A*. Found paths are cached until Invalidate is called, and the work done
+// per tick is limited by PathBudget.
+type Pathfinder struct {
+	solid  func(i, j int) bool
+	cache  map[route][]image.Point
+	budget int
+
+	// tick counts the calls to Refill. Searches cut short by the budget
+	// wait in searches for the next tick, and routes given up on stay in
+	// failed until the tick they may be tried again.
+	tick     uint64
+	searches map[route]*search
+	failed   map[route]uint64
+}
+
+// search is the state of A* between two tiles, kept between ticks.
+type search struct {
+	came     map[image.Point]image.Point
+	cost     map[image.Point]float64
+	open     *frontier
+	explored int
+
+	// tick is the last tick the search was worked on.
+	tick uint64
+}
+
+// NewPathfinder returns a pathfinder over tiles for which solid reports
+// whether they are in the way, like Level.Solid.
+func NewPathfinder(solid func(i, j int) bool) *Pathfinder {
+	return &Pathfinder{
+		solid:    solid,
+		cache:    map[route][]image.Point{},
+		budget:   PathBudget,
+		searches: map[route]*search{},
+		failed:   map[route]uint64{},
+	}
+}
+
+// Refill gives the pathfinder the budget of a new tick. Searches nobody
+// carried on during the last tick are dropped.
+func (finder *Pathfinder) Refill() {
+	finder.budget = PathBudget
+	for r, s := range finder.searches {
+		if s.tick < finder.tick {
+			delete(finder.searches, r)
+		}
+	}
+	finder.tick++
+}
+
+// Invalidate forgets the cached paths, for when the level has changed.
+func (finder *Pathfinder) Invalidate() {
+	finder.cache = map[route][]image.Point{}
+	finder.searches = map[route]*search{}
+	finder.failed = map[route]uint64{}
+}
+
+// Find returns the tiles to walk through to get from one tile to another,
+// without the tile it starts from. It returns false when there is no way,
+// the goal is too far to search for, or the budget of this tick ran out
+// before one was found.
+func (finder *Pathfinder) Find(from, to image.Point) ([]image.Point, bool) {
+	r := route{from, to}
+	if path, ok := finder.cache[r]; ok {
+		return path, path != nil
+	}
+	if until, ok := finder.failed[r]; ok {
+		if finder.tick < until {
+			return nil, false
+		}
+		delete(finder.failed, r)
+	}
+	if finder.budget <= 0 {
+		return nil, false
+	}
+
+	s := finder.searches[r]
+	if s == nil {
+		s = finder.start(from, to)
+		finder.searches[r] = s
+	}
+	s.tick = finder.tick
+	path, done := finder.run(s, from, to)
+	if !done {
+		return nil, false
+	}
+	delete(finder.searches, r)
+
+	if path == nil && s.explored > searchLimit {
+		if len(finder.failed) >= maxCachedPaths {
+			finder.failed = map[route]uint64{}
+		}
+		finder.failed[r] = finder.tick + failedSearchTicks
+		return nil, false
+	}
+	if len(finder.cache) >= maxCachedPaths {
+		finder.cache = map[route][]image.Point{}
+	}
+	finder.cache[r] = path
+
+	return path, path != nil
+}
+
+// start begins a search from one tile to another.
+func (finder *Pathfinder) start(from, to image.Point) *search {
+	s := &search{
+		came: map[image.Point]image.Point{},
+		cost: map[image.Point]float64{from: 0},
+		open: &frontier{},
+	}
+	if !finder.solid(to.X, to.Y) {
+		heap.Push(s.open, &node{point: from, estimate: octile(from, to)})
+	}
+	return s
+}
+
+// run carries on with A* until it finds the way, runs out of tiles or
+// gives up after searchLimit of them. It reports false when the budget ran
+// out first; otherwise the path is nil when none was found.
+func (finder *Pathfinder) run(s *search, from, to image.Point) ([]image.Point, bool) {
+	for s.open.Len() > 0 {
+		if finder.budget <= 0 {
+			return nil, false
+		}
+		if s.explored > searchLimit {
+			return nil, true
+		}
+
+		current := heap.Pop(s.open).(*node)
+		if current.point == to {
+			var path []image.Point
+			for point := to; point != from; point = s.came[point] {
+				path = append([]image.Point{point}, path...)
+			}
+			return path, true
+		}
+		if current.cost > s.cost[current.point] {
+			continue
+		}
+
+		finder.budget--
+		s.explored++
+		for _, step := range finder.steps(current.point) {
+			next := current.point.Add(step)
+			c := current.cost + math.Hypot(float64(step.X), float64(step.Y))
+			if old, ok := s.cost[next]; ok && old <= c {
+				continue
+			}
+			s.cost[next] = c
+			s.came[next] = current.point
+			heap.Push(s.open, &node{point: next, cost: c, estimate: c + octile(next, to)})
+		}
+	}
+
+	return nil, true
+}
+
+// neighbors are the eight directions a path can go from a tile.
+var neighbors = []image.Point{
+	{1, 0}, {-1, 0}, {0, 1}, {0, -1},
+	{1, 1}, {1, -1}, {-1, 1}, {-1, -1},
+}
+
+// steps returns the ways out of a tile onto open tiles. Diagonal steps are
+// only allowed when both tiles beside them are open, so paths do not cut
+// the corners of walls.
+func (finder *Pathfinder) steps(point image.Point) []image.Point {
+	var steps []image.Point
+	for _, step := range neighbors {
+		next := point.Add(step)
+		if finder.solid(next.X, next.Y) {
+			continue
+		}
+		if step.X != 0 && step.Y != 0 &&
+			(finder.solid(point.X+step.X, point.Y) || finder.solid(point.X, point.Y+step.Y)) {
+			continue
+		}
+		steps = append(steps, step)
+	}
+
+	return steps
+}
+
+// octile is the length of the shortest way between two tiles on an open
+// grid with diagonal steps.
+func octile(a, b image.Point) float64 {
+	dx := math.Abs(float64(a.X - b.X))
+	dy := math.Abs(float64(a.Y - b.Y))
+	return math.Max(dx, dy) + (math.Sqrt2-1)*math.Min(dx, dy)
+}
+
+// node is a tile waiting to be explored.
+type node struct {
+	point    image.Point
+	cost     float64
+	estimate float64
+}
+
+// frontier is the open set of A*, a heap ordered by estimate.
+type frontier []*node
+
+func (f frontier) Len() int            { return len(f) }
+func (f frontier) Less(i, j int) bool  { return f[i].estimate < f[j].estimate }
+func (f frontier) Swap(i, j int)       { f[i], f[j] = f[j], f[i] }
+func (f *frontier) Push(x interface{}) { *f = append(*f, x.(*node)) }
+func (f *frontier) Pop() interface{} {
+	old := *f
+	n := old[len(old)-1]
+	*f = old[:len(old)-1]
+	return n
+}
+
+// tile returns the tile under the middle of the unit's collider.
+func (world *World) tile(unit *Unit) image.Point {
+	x, y := world.center(unit)
+	return image.Pt(int(math.Floor(x/TileSize)), int(math.Floor(y/TileSize)))
+}
+
+// adjacent reports whether two tiles touch, diagonals included.
+func adjacent(a, b image.Point) bool {
+	d := a.Sub(b)
+	return d.X >= -1 && d.X <= 1 && d.Y >= -1 && d.Y <= 1
+}
+
+// steer returns the direction an NPC should walk in to reach x, y in
+// pixels, following a path around walls when there is no straight way.
+// While no path is known yet it heads straight for the goal.
+func (world *World) steer(npc *NPC, unit *Unit, x, y float64) (float64, float64) {
+	cx, cy := world.center(unit)
+	if world.Level == nil {
+		return x - cx, y - cy
+	}
+	if world.paths == nil {
+		world.paths = NewPathfinder(world.impassable)
+	}
+
+	from := world.tile(unit)
+	to := image.Pt(int(math.Floor(x/TileSize)), int(math.Floor(y/TileSize)))
+	if from == to {
+		return x - cx, y - cy
+	}
+
+	if npc.goal != to || len(npc.path) == 0 || !adjacent(from, npc.path[0]) {
+		npc.path, _ = world.paths.Find(from, to)
+		npc.goal = to
+	}
+	// Drop the waypoints already reached.
+	for i, point := range npc.path {
+		if point == from {
+			npc.path = npc.path[i+1:]
+			break
+		}
+	}
+	if len(npc.path) == 0 {
+		return x - cx, y - cy
+	}
+
+	next := npc.path[0]
+	return (float64(next.X)+0.5)*TileSize - cx, (float64(next.Y)+0.5)*TileSize - cy
+}
@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"image"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []string
+		from image.Point
+		to   image.Point
+		want []image.Point
+	}{
+		{
+			name: "corridor",
+			rows: []string{
+				"#######",
+				"#.....#",
+				"#######",
+			},
+			from: image.Pt(1, 1),
+			to:   image.Pt(5, 1),
+			want: []image.Point{{2, 1}, {3, 1}, {4, 1}, {5, 1}},
+		},
+		{
+			name: "dead end",
+			rows: []string{
+				"######",
+				"#....#",
+				"#.##.#",
+				"#.#..#",
+				"######",
+			},
+			from: image.Pt(1, 3),
+			to:   image.Pt(3, 3),
+			want: []image.Point{{1, 2}, {1, 1}, {2, 1}, {3, 1}, {4, 1}, {4, 2}, {4, 3}, {3, 3}},
+		},
+		{
+			name: "unreachable",
+			rows: []string{
+				"#######",
+				"#..#..#",
+				"#..#..#",
+				"#######",
+			},
+			from: image.Pt(1, 1),
+			to:   image.Pt(5, 2),
+			want: nil,
+		},
+		{
+			name: "corner",
+			rows: []string{
+				"####",
+				"#..#",
+				"##.#",
+				"####",
+			},
+			from: image.Pt(1, 1),
+			to:   image.Pt(2, 2),
+			want: []image.Point{{2, 1}, {2, 2}},
+		},
+		{
+			name: "wall as goal",
+			rows: []string{
+				"#####",
+				"#...#",
+				"#####",
+			},
+			from: image.Pt(1, 1),
+			to:   image.Pt(2, 0),
+			want: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			finder := NewPathfinder(testLevel(test.rows...).Solid)
+			path, ok := finder.Find(test.from, test.to)
+			if ok != (test.want != nil) {
+				t.Fatalf("found %v, want %v", ok, test.want != nil)
+			}
+			if len(path) != len(test.want) {
+				t.Fatalf("path %v, want %v", path, test.want)
+			}
+			for i := range path {
+				if path[i] != test.want[i] {
+					t.Fatalf("path %v, want %v", path, test.want)
+				}
+			}
+		})
+	}
+}
+
+// openRoom returns the rows of an open room with walls around it.
+func openRoom(w, h int) []string {
+	rows := make([]string, h)
+	for j := range rows {
+		row := make([]byte, w)
+		for i := range row {
+			row[i] = '.'
+			if i == 0 || j == 0 || i == w-1 || j == h-1 {
+				row[i] = '#'
+			}
+		}
+		rows[j] = string(row)
+	}
+	return rows
+}
+
+// TestFindResumes checks that a search cut short by the budget of a tick
+// carries on where it stopped on the next one.
+func TestFindResumes(t *testing.T) {
+	finder := NewPathfinder(testLevel(openRoom(40, 40)...).Solid)
+	finder.budget = 10
+
+	from, to := image.Pt(1, 1), image.Pt(38, 38)
+	ticks := 0
+	for {
+		if _, ok := finder.Find(from, to); ok {
+			break
+		}
+		ticks++
+		if ticks > 100 {
+			t.Fatal("search never finished")
+		}
+		finder.Refill()
+		finder.budget = 10
+	}
+	if ticks < 2 {
+		t.Errorf("search finished in %d ticks, want it spread over several", ticks)
+	}
+}
+
+// TestFindGivesUp checks that an unreachable goal in a large area is given
+// up on after searchLimit tiles, and not searched for again right away.
+func TestFindGivesUp(t *testing.T) {
+	// A room with more tiles than searchLimit and a walled off pocket in
+	// its bottom right corner.
+	const w, h = 80, 80
+	rows := openRoom(w, h)
+	rows[h-4] = "#" + rows[h-4][1:w-4] + "####"
+	rows[h-3] = "#" + rows[h-3][1:w-3] + "#.#"
+	rows[h-2] = "#" + rows[h-2][1:w-4] + "##.#"
+	finder := NewPathfinder(testLevel(rows...).Solid)
+
+	from, to := image.Pt(1, 1), image.Pt(w-2, h-3)
+	r := route{from, to}
+	spent := 0
+	for tick := 0; ; tick++ {
+		if _, ok := finder.Find(from, to); ok {
+			t.Fatal("found a way to an unreachable goal")
+		}
+		spent += PathBudget - finder.budget
+		if finder.searches[r] == nil {
+			break
+		}
+		if tick > 10 {
+			t.Fatal("search never gave up")
+		}
+		finder.Refill()
+	}
+	if spent > searchLimit+1 {
+		t.Errorf("search explored %d tiles, want at most %d", spent, searchLimit+1)
+	}
+	if _, ok := finder.failed[r]; !ok {
+		t.Fatal("search was not given up on")
+	}
+
+	finder.Refill()
+	if _, ok := finder.Find(from, to); ok || finder.budget != PathBudget {
+		t.Errorf("given up goal searched again, %d tiles explored", PathBudget-finder.budget)
+	}
+
+	for n := 0; n < failedSearchTicks; n++ {
+		finder.Refill()
+	}
+	finder.Find(from, to)
+	if finder.budget == PathBudget {
+		t.Error("given up goal not searched again after failedSearchTicks")
+	}
+}
@@ -0,0 +1,70 @@
+package internal
+
+// input is a command issued by the local player that the server may not
+// have applied yet, together with how long it has been held.
+type input struct {
+	event    *Event
+	sequence uint32
+	ticks    uint32
+}
+
+// Predict numbers a move or idle event of the local player and applies it
+// right away, so the hero reacts without waiting for the server. The event
+// is kept until a snapshot acknowledges it. Attacks do not move the hero
+// and are only applied, to show the swing; other events, like using items,
+// are left to the server. Like Step, it must not be called concurrently
+// with other changes to the world.
+func (world *World) Predict(event *Event) {
+	switch event.GetType() {
+	case Event_type_attack:
+		world.applyInput(event)
+		return
+	case Event_type_move:
+		world.sequence++
+		event.GetMove().Sequence = world.sequence
+	case Event_type_idle:
+		world.sequence++
+		event.GetIdle().Sequence = world.sequence
+	default:
+		return
+	}
+
+	world.applyInput(event)
+	world.pending = append(world.pending, &input{
+		event:    event,
+		sequence: world.sequence,
+	})
+}
+
+// countInputTicks charges the elapsed tick to the input that is currently
+// in effect for the local player.
+func (world *World) countInputTicks() {
+	if len(world.pending) > 0 {
+		world.pending[len(world.pending)-1].ticks++
+	} else if world.acked != nil {
+		world.acked.ticks++
+	}
+}
+
+// replay rebuilds the local player on top of the authoritative state in
+// unit: inputs the server has applied are dropped, the last of them is held
+// for as long as it was held locally, and the rest are simulated again.
+func (world *World) replay(unit *Unit) {
+	for len(world.pending) > 0 && world.pending[0].sequence <= unit.Sequence {
+		world.acked = world.pending[0]
+		world.pending = world.pending[1:]
+	}
+
+	if world.acked != nil && world.acked.sequence == unit.Sequence {
+		for ticks := unit.InputTicks; ticks < world.acked.ticks; ticks++ {
+			world.advance(unit)
+		}
+	}
+
+	for _, in := range world.pending {
+		world.applyInput(in.event)
+		for i := uint32(0); i < in.ticks; i++ {
+			world.advance(unit)
+		}
+	}
+}
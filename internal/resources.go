@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"image"
 	"io"
-	"log"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -17,190 +16,179 @@ type Frames struct {
 	image.Config
 }
 
+// animations lists the sprites made of several frames, with their number
+// of frames. The files are named <name>_anim_f<frame>.png.
+var animations = map[string]int{
+	"big_demon_idle":   4,
+	"big_demon_run":    4,
+	"big_zombie_idle":  4,
+	"big_zombie_run":   4,
+	"chest_empty_open": 3,
+	"chest_full_open":  3,
+	"chest_mimic_open": 3,
+	"chort_idle":       4,
+	"chort_run":        4,
+	"coin":             4,
+	"elf_f_hit":        1,
+	"elf_f_idle":       4,
+	"elf_f_run":        4,
+	"floor_spikes":     4,
+	"goblin_idle":      4,
+	"goblin_run":       4,
+	"imp_idle":         4,
+	"imp_run":          4,
+	"necromancer_idle": 4,
+	"necromancer_run":  4,
+	"ogre_idle":        4,
+	"ogre_run":         4,
+	"orc_warrior_idle": 4,
+	"orc_warrior_run":  4,
+	"skelet_idle":      4,
+	"skelet_run":       4,
+
+	"wall_fountain_basin_blue": 3,
+	"wall_fountain_basin_red":  3,
+	"wall_fountain_mid_blue":   3,
+	"wall_fountain_mid_red":    3,
+}
+
+// SkinSprites maps skins that have no animations of their own to the
+// sprite they use for every action.
+var SkinSprites = map[string]string{
+	"mimic": "chest_mimic_open",
+}
+
+// images lists the sprites made of a single file named <name>.png.
+var images = []string{
+	"floor_1",
+	"floor_2",
+	"floor_3",
+	"floor_4",
+	"floor_5",
+	"floor_6",
+	"floor_7",
+	"floor_8",
+	"doors_leaf_closed",
+	"doors_leaf_open",
+	"floor_ladder",
+	"hole",
+	"flask_big_blue",
+	"flask_big_green",
+	"flask_big_red",
+	"flask_big_yellow",
+	"flask_blue",
+	"flask_green",
+	"flask_red",
+	"flask_yellow",
+	"ui_heart_empty",
+	"ui_heart_full",
+	"ui_heart_half",
+	"column_mid",
+	"column_top",
+	"edge",
+	"wall_banner_blue",
+	"wall_banner_green",
+	"wall_banner_red",
+	"wall_banner_yellow",
+	"wall_column_mid",
+	"wall_column_top",
+	"wall_corner_bottom_left",
+	"wall_corner_bottom_right",
+	"wall_corner_front_left",
+	"wall_corner_front_right",
+	"wall_corner_left",
+	"wall_corner_right",
+	"wall_corner_top_left",
+	"wall_corner_top_right",
+	"wall_coulmn_base",
+	"wall_fountain_top",
+	"wall_goo",
+	"wall_goo_base",
+	"wall_hole_1",
+	"wall_hole_2",
+	"wall_inner_corner_l_top_left",
+	"wall_inner_corner_l_top_rigth",
+	"wall_inner_corner_mid_left",
+	"wall_inner_corner_mid_rigth",
+	"wall_inner_corner_t_top_left",
+	"wall_inner_corner_t_top_rigth",
+	"wall_left",
+	"wall_mid",
+	"wall_right",
+	"wall_side_front_left",
+	"wall_side_front_right",
+	"wall_side_mid_left",
+	"wall_side_mid_right",
+	"wall_side_top_left",
+	"wall_side_top_right",
+	"wall_top_left",
+	"wall_top_mid",
+	"wall_top_right",
+	"weapon_axe",
+	"weapon_baton_with_spikes",
+	"weapon_big_hammer",
+	"weapon_cleaver",
+	"weapon_katana",
+	"weapon_knife",
+	"weapon_mace",
+	"weapon_red_magic_staff",
+	"weapon_regular_sword",
+	"weapon_rusty_sword",
+	"weapon_spear",
+}
+
 func LoadResources() (map[string]Frames, error) {
-	images := map[string]image.Image{}
-	cfgs := map[string]image.Config{}
 	sprites := map[string]Frames{}
 
-	requiredFiles := []string{
-		"big_demon_idle_anim_f0.png",
-		"big_demon_idle_anim_f1.png",
-		"big_demon_idle_anim_f2.png",
-		"big_demon_idle_anim_f3.png",
-		"big_demon_run_anim_f0.png",
-		"big_demon_run_anim_f1.png",
-		"big_demon_run_anim_f2.png",
-		"big_demon_run_anim_f3.png",
-		"big_zombie_idle_anim_f0.png",
-		"big_zombie_idle_anim_f1.png",
-		"big_zombie_idle_anim_f2.png",
-		"big_zombie_idle_anim_f3.png",
-		"big_zombie_run_anim_f0.png",
-		"big_zombie_run_anim_f1.png",
-		"big_zombie_run_anim_f2.png",
-		"big_zombie_run_anim_f3.png",
-		"elf_f_idle_anim_f0.png",
-		"elf_f_idle_anim_f1.png",
-		"elf_f_idle_anim_f2.png",
-		"elf_f_idle_anim_f3.png",
-		"elf_f_run_anim_f0.png",
-		"elf_f_run_anim_f1.png",
-		"elf_f_run_anim_f2.png",
-		"elf_f_run_anim_f3.png",
-		"floor_1.png",
-		"floor_2.png",
-		"floor_3.png",
-		"floor_4.png",
-		"floor_5.png",
-		"floor_6.png",
-		"floor_7.png",
-		"floor_8.png",
+	for name, count := range animations {
+		sprite := Frames{}
+		for i := 0; i < count; i++ {
+			img, cfg, err := loadImage(fmt.Sprintf("%s_anim_f%d.png", name, i))
+			if err != nil {
+				return sprites, err
+			}
+			sprite.Frames = append(sprite.Frames, img)
+			sprite.Config = cfg
+		}
+		sprites[name] = sprite
 	}
 
-	for _, fileName := range requiredFiles {
-		filePath := filepath.Join("asset", "sprites", fileName)
-
-		fileBytes, err := readFile(filePath)
-		if err != nil {
-			return sprites, err
-		}
+	for skin, sprite := range SkinSprites {
+		sprites[skin+"_"+UnitActionIdle] = sprites[sprite]
+		sprites[skin+"_"+UnitActionMove] = sprites[sprite]
+	}
 
-		img, _, err := image.Decode(bytes.NewReader(fileBytes))
+	for _, name := range images {
+		img, cfg, err := loadImage(name + ".png")
 		if err != nil {
 			return sprites, err
 		}
-
-		fileCfg, err := readFile(filePath)
-		if err != nil {
-			return sprites, err
+		sprites[name] = Frames{
+			Frames: []image.Image{img},
+			Config: cfg,
 		}
+	}
 
-		cfg, _, err := image.DecodeConfig(bytes.NewReader(fileCfg))
-		if err != nil {
-			log.Fatal(err)
-		}
+	return sprites, nil
+}
 
-		images[fileName] = img
-		cfgs[fileName] = cfg
+func loadImage(fileName string) (image.Image, image.Config, error) {
+	fileBytes, err := readFile(filepath.Join("asset", "sprites", fileName))
+	if err != nil {
+		return nil, image.Config{}, err
 	}
 
-	sprites["big_demon_idle"] = Frames{
-		Frames: []image.Image{
-			images["big_demon_idle_anim_f0.png"],
-			images["big_demon_idle_anim_f1.png"],
-			images["big_demon_idle_anim_f2.png"],
-			images["big_demon_idle_anim_f3.png"],
-		},
-		Config: cfgs["big_demon_idle_anim_f0.png"],
-	}
-	sprites["big_demon_run"] = Frames{
-		Frames: []image.Image{
-			images["big_demon_run_anim_f0.png"],
-			images["big_demon_run_anim_f1.png"],
-			images["big_demon_run_anim_f2.png"],
-			images["big_demon_run_anim_f3.png"],
-		},
-		Config: cfgs["big_demon_run_anim_f0.png"],
-	}
-	sprites["big_zombie_idle"] = Frames{
-		Frames: []image.Image{
-			images["big_zombie_idle_anim_f0.png"],
-			images["big_zombie_idle_anim_f1.png"],
-			images["big_zombie_idle_anim_f2.png"],
-			images["big_zombie_idle_anim_f3.png"],
-		},
-		Config: cfgs["big_zombie_idle_anim_f0.png"],
-	}
-	sprites["big_zombie_run"] = Frames{
-		Frames: []image.Image{
-			images["big_zombie_run_anim_f0.png"],
-			images["big_zombie_run_anim_f1.png"],
-			images["big_zombie_run_anim_f2.png"],
-			images["big_zombie_run_anim_f3.png"],
-		},
-		Config: cfgs["big_zombie_run_anim_f0.png"],
-	}
-	sprites["elf_f_idle"] = Frames{
-		Frames: []image.Image{
-			images["elf_f_idle_anim_f0.png"],
-			images["elf_f_idle_anim_f1.png"],
-			images["elf_f_idle_anim_f2.png"],
-			images["elf_f_idle_anim_f3.png"],
-		},
-		Config: cfgs["elf_f_idle_anim_f0.png"],
-	}
-	sprites["elf_f_run"] = Frames{
-		Frames: []image.Image{
-			images["elf_f_run_anim_f0.png"],
-			images["elf_f_run_anim_f1.png"],
-			images["elf_f_run_anim_f2.png"],
-			images["elf_f_run_anim_f3.png"],
-		},
-		Config: cfgs["elf_f_run_anim_f0.png"],
-	}
-	sprites["floor_1"] = Frames{
-		Frames: []image.Image{images["floor_1.png"]},
-		Config: cfgs["floor_1.png"],
-	}
-	sprites["floor_2"] = Frames{
-		Frames: []image.Image{images["floor_2.png"]},
-		Config: cfgs["floor_2.png"],
-	}
-	sprites["floor_3"] = Frames{
-		Frames: []image.Image{images["floor_3.png"]},
-		Config: cfgs["floor_3.png"],
-	}
-	sprites["floor_4"] = Frames{
-		Frames: []image.Image{images["floor_4.png"]},
-		Config: cfgs["floor_4.png"],
-	}
-	sprites["floor_5"] = Frames{
-		Frames: []image.Image{images["floor_5.png"]},
-		Config: cfgs["floor_5.png"],
-	}
-	sprites["floor_6"] = Frames{
-		Frames: []image.Image{images["floor_6.png"]},
-		Config: cfgs["floor_6.png"],
-	}
-	sprites["floor_7"] = Frames{
-		Frames: []image.Image{images["floor_7.png"]},
-		Config: cfgs["floor_7.png"],
-	}
-	sprites["floor_8"] = Frames{
-		Frames: []image.Image{images["floor_8.png"]},
-		Config: cfgs["floor_8.png"],
+	img, _, err := image.Decode(bytes.NewReader(fileBytes))
+	if err != nil {
+		return nil, image.Config{}, fmt.Errorf("decode %s: %w", fileName, err)
 	}
 
-	return sprites, nil
-}
-
-func LoadLevel() [][]string {
-	a := "floor_1"
-	b := "floor_2"
-	c := "floor_3"
-	d := "floor_4"
-
-	level := [][]string{
-		{a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a},
-		{a, a, b, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a},
-		{a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a},
-		{a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, c, a, a, a},
-		{a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a},
-		{a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a},
-		{a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a},
-		{a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a},
-		{a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a},
-		{a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a},
-		{a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a},
-		{a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a},
-		{a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a},
-		{a, a, a, a, a, a, c, a, a, a, a, a, a, a, a, a, a, a, a, a, a},
-		{a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a},
-		{a, a, a, a, a, a, a, a, a, a, a, a, a, d, a, a, a, a, a, a, a},
-		{a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a, a},
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(fileBytes))
+	if err != nil {
+		return nil, image.Config{}, fmt.Errorf("decode %s: %w", fileName, err)
 	}
 
-	return level
+	return img, cfg, nil
 }
 
 func open(name string) (io.ReadCloser, error) {
@@ -211,6 +199,10 @@ func open(name string) (io.ReadCloser, error) {
 		if err != nil {
 			return nil, err
 		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("get %s: %s", name, resp.Status)
+		}
 		return resp.Body, nil
 	}
 
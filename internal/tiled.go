@@ -0,0 +1,544 @@
+package internal
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Maps made with Tiled (https://www.mapeditor.org) are read from JSON
+// (.tmj) and XML (.tmx) files, with their tilesets either embedded or in
+// .tsj and .tsx files next to them. Tilesets must be image collections
+// whose images are our sprites, so a tile is known by the sprite name.
+//
+// Tiles with a boolean "solid" property block units. Objects are known by
+// their class (or type): "spawn" marks where units may appear, and the
+// kinds of Object are placed as such, with "state", "target", "timer" and
+// "sprite" properties. Decorations show their "sprite", or the tile of a
+// tile object. The map property "monsters" is the count of the spawn table
+// and "spawn_<skin>" and "patrol_<skin>" fill its entries.
+
+// tiledGIDMask clears the flip flags of a global tile id.
+const tiledGIDMask = 0x1fffffff
+
+// tiledMap is a map in either format.
+type tiledMap struct {
+	Width      int
+	Height     int
+	Properties map[string]string
+	TileLayers [][]uint32
+	Objects    []tiledObject
+	Tilesets   []tiledTileset
+}
+
+type tiledObject struct {
+	ID         int
+	Name       string
+	Class      string
+	X          float64
+	Y          float64
+	Width      float64
+	Height     float64
+	GID        uint32
+	Properties map[string]string
+}
+
+type tiledTileset struct {
+	FirstGID uint32
+	// Sprites by local tile id, and the properties of the tiles.
+	Sprites    map[uint32]string
+	Properties map[uint32]map[string]string
+}
+
+// LoadLevel reads the level with the given name from asset/levels, from a
+// .tmj file or else a .tmx one.
+func LoadLevel(name string) (*Level, error) {
+	dir := path.Join("asset", "levels")
+
+	data, err := readFile(path.Join(dir, name+".tmj"))
+	if err == nil {
+		return parseLevel(name, dir, data, parseTMJ)
+	}
+
+	data, err = readFile(path.Join(dir, name+".tmx"))
+	if err != nil {
+		return nil, fmt.Errorf("level %s: %w", name, err)
+	}
+	return parseLevel(name, dir, data, parseTMX)
+}
+
+func parseLevel(name, dir string, data []byte, parse func(dir string, data []byte) (*tiledMap, error)) (*Level, error) {
+	m, err := parse(dir, data)
+	if err != nil {
+		return nil, fmt.Errorf("level %s: %w", name, err)
+	}
+
+	level, err := m.level(name)
+	if err != nil {
+		return nil, fmt.Errorf("level %s: %w", name, err)
+	}
+	return level, nil
+}
+
+// level turns the map into a level.
+func (m *tiledMap) level(name string) (*Level, error) {
+	level := &Level{Name: name, Solids: map[string]bool{}}
+
+	for _, tileset := range m.Tilesets {
+		for id, properties := range tileset.Properties {
+			if solid, ok := properties["solid"]; ok && tileset.Sprites[id] != "" {
+				level.Solids[tileset.Sprites[id]] = solid == "true"
+			}
+		}
+	}
+
+	for _, data := range m.TileLayers {
+		if len(data) != m.Width*m.Height {
+			return nil, fmt.Errorf("tile layer has %d tiles, want %d", len(data), m.Width*m.Height)
+		}
+
+		layer := make([][]string, m.Height)
+		for j := range layer {
+			layer[j] = make([]string, m.Width)
+			for i := range layer[j] {
+				sprite, err := m.sprite(data[j*m.Width+i])
+				if err != nil {
+					return nil, err
+				}
+				layer[j][i] = sprite
+			}
+		}
+		level.Layers = append(level.Layers, layer)
+	}
+
+	for _, object := range m.Objects {
+		if object.GID != 0 {
+			// Tile objects are placed by their bottom left corner.
+			object.Y -= object.Height
+		}
+		x, y := object.X+object.Width/2, object.Y+object.Height/2
+
+		if object.Class == "spawn" {
+			level.Spawns = append(level.Spawns, image.Pt(int(x), int(y)))
+			continue
+		}
+
+		id := object.Name
+		if id == "" {
+			id = fmt.Sprintf("object_%d", object.ID)
+		}
+		placed := &Object{
+			Id:     id,
+			Kind:   object.Class,
+			X:      x,
+			Y:      y,
+			Width:  object.Width,
+			Height: object.Height,
+			State:  object.Properties["state"],
+			Target: object.Properties["target"],
+		}
+		if timer, err := strconv.ParseUint(object.Properties["timer"], 10, 32); err == nil {
+			placed.Timer = uint32(timer)
+		}
+
+		switch object.Class {
+		case ObjectChest:
+			placed.State = ChestClosed
+		case ObjectDoor:
+			if placed.State != DoorOpen {
+				placed.State = DoorClosed
+			}
+		case ObjectSpikes:
+			placed.State = SpikesDown
+		case ObjectPit, ObjectLadder:
+		case ObjectDecoration:
+			placed.State = object.Properties["sprite"]
+			if placed.State == "" {
+				sprite, err := m.sprite(object.GID)
+				if err != nil {
+					return nil, err
+				}
+				placed.State = sprite
+			}
+		default:
+			return nil, fmt.Errorf("object %s: unknown class %q", id, object.Class)
+		}
+		level.Objects = append(level.Objects, placed)
+	}
+
+	if count, err := strconv.Atoi(m.Properties["monsters"]); err == nil {
+		level.SpawnTable.Count = count
+	}
+	for _, skin := range sortedKeys(m.Properties) {
+		if !strings.HasPrefix(skin, "spawn_") {
+			continue
+		}
+		weight, err := strconv.Atoi(m.Properties[skin])
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", skin, err)
+		}
+		skin = strings.TrimPrefix(skin, "spawn_")
+		level.SpawnTable.Entries = append(level.SpawnTable.Entries, SpawnEntry{
+			Skin:   skin,
+			Weight: weight,
+			Patrol: m.Properties["patrol_"+skin] == "true",
+		})
+	}
+
+	return level, nil
+}
+
+// sprite returns the sprite of a global tile id; 0 is no tile.
+func (m *tiledMap) sprite(gid uint32) (string, error) {
+	gid &= tiledGIDMask
+	if gid == 0 {
+		return "", nil
+	}
+
+	var found *tiledTileset
+	for i := range m.Tilesets {
+		if m.Tilesets[i].FirstGID <= gid && (found == nil || m.Tilesets[i].FirstGID > found.FirstGID) {
+			found = &m.Tilesets[i]
+		}
+	}
+	if found == nil {
+		return "", fmt.Errorf("tile %d is in no tileset", gid)
+	}
+
+	sprite, ok := found.Sprites[gid-found.FirstGID]
+	if !ok {
+		return "", fmt.Errorf("tile %d has no image", gid)
+	}
+	return sprite, nil
+}
+
+// spriteName returns the sprite an image of a tileset shows. Animations
+// are named by their first frame.
+func spriteName(source string) string {
+	name := strings.TrimSuffix(path.Base(source), path.Ext(source))
+	return strings.TrimSuffix(name, "_anim_f0")
+}
+
+func sortedKeys(properties map[string]string) []string {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// JSON format.
+
+type tmjMap struct {
+	Width      int           `json:"width"`
+	Height     int           `json:"height"`
+	Properties []tmjProperty `json:"properties"`
+	Layers     []tmjLayer    `json:"layers"`
+	Tilesets   []tmjTileset  `json:"tilesets"`
+}
+
+type tmjProperty struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+type tmjLayer struct {
+	Type    string          `json:"type"`
+	Data    json.RawMessage `json:"data"`
+	Objects []tmjObject     `json:"objects"`
+}
+
+type tmjObject struct {
+	ID         int           `json:"id"`
+	Name       string        `json:"name"`
+	Type       string        `json:"type"`
+	Class      string        `json:"class"`
+	X          float64       `json:"x"`
+	Y          float64       `json:"y"`
+	Width      float64       `json:"width"`
+	Height     float64       `json:"height"`
+	GID        uint32        `json:"gid"`
+	Properties []tmjProperty `json:"properties"`
+}
+
+type tmjTileset struct {
+	FirstGID uint32    `json:"firstgid"`
+	Source   string    `json:"source"`
+	Image    string    `json:"image"`
+	Tiles    []tmjTile `json:"tiles"`
+}
+
+type tmjTile struct {
+	ID         uint32        `json:"id"`
+	Image      string        `json:"image"`
+	Properties []tmjProperty `json:"properties"`
+}
+
+func tmjProperties(properties []tmjProperty) map[string]string {
+	values := map[string]string{}
+	for _, property := range properties {
+		values[property.Name] = fmt.Sprint(property.Value)
+	}
+	return values
+}
+
+func parseTMJ(dir string, data []byte) (*tiledMap, error) {
+	var raw tmjMap
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	m := &tiledMap{
+		Width:      raw.Width,
+		Height:     raw.Height,
+		Properties: tmjProperties(raw.Properties),
+	}
+	for _, layer := range raw.Layers {
+		switch layer.Type {
+		case "tilelayer":
+			var tiles []uint32
+			if err := json.Unmarshal(layer.Data, &tiles); err != nil {
+				return nil, fmt.Errorf("tile layer: only uncompressed arrays are supported: %w", err)
+			}
+			m.TileLayers = append(m.TileLayers, tiles)
+
+		case "objectgroup":
+			for _, object := range layer.Objects {
+				class := object.Class
+				if class == "" {
+					class = object.Type
+				}
+				m.Objects = append(m.Objects, tiledObject{
+					ID:         object.ID,
+					Name:       object.Name,
+					Class:      class,
+					X:          object.X,
+					Y:          object.Y,
+					Width:      object.Width,
+					Height:     object.Height,
+					GID:        object.GID,
+					Properties: tmjProperties(object.Properties),
+				})
+			}
+		}
+	}
+
+	for _, ref := range raw.Tilesets {
+		if ref.Source != "" {
+			t, err := loadTileset(dir, ref.Source, ref.FirstGID)
+			if err != nil {
+				return nil, err
+			}
+			m.Tilesets = append(m.Tilesets, t)
+			continue
+		}
+
+		t, err := ref.tileset(dir, ref.FirstGID)
+		if err != nil {
+			return nil, err
+		}
+		m.Tilesets = append(m.Tilesets, t)
+	}
+
+	return m, nil
+}
+
+func (raw *tmjTileset) tileset(dir string, firstGID uint32) (tiledTileset, error) {
+	if raw.Image != "" {
+		return tiledTileset{}, fmt.Errorf("only image collections are supported")
+	}
+
+	t := tiledTileset{
+		FirstGID:   firstGID,
+		Sprites:    map[uint32]string{},
+		Properties: map[uint32]map[string]string{},
+	}
+	for _, tile := range raw.Tiles {
+		t.Sprites[tile.ID] = spriteName(path.Join(dir, tile.Image))
+		t.Properties[tile.ID] = tmjProperties(tile.Properties)
+	}
+	return t, nil
+}
+
+// XML format.
+
+type tmxMap struct {
+	Width      int           `xml:"width,attr"`
+	Height     int           `xml:"height,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Tilesets   []tmxTileset  `xml:"tileset"`
+	Layers     []tmxLayer    `xml:"layer"`
+	Groups     []tmxGroup    `xml:"objectgroup"`
+}
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type tmxTileset struct {
+	FirstGID uint32    `xml:"firstgid,attr"`
+	Source   string    `xml:"source,attr"`
+	Image    *tmxImage `xml:"image"`
+	Tiles    []tmxTile `xml:"tile"`
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+}
+
+type tmxTile struct {
+	ID         uint32        `xml:"id,attr"`
+	Image      tmxImage      `xml:"image"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxLayer struct {
+	Data struct {
+		Encoding string `xml:"encoding,attr"`
+		Text     string `xml:",chardata"`
+	} `xml:"data"`
+}
+
+type tmxGroup struct {
+	Objects []tmxObject `xml:"object"`
+}
+
+type tmxObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	GID        uint32        `xml:"gid,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+func tmxProperties(properties []tmxProperty) map[string]string {
+	values := map[string]string{}
+	for _, property := range properties {
+		values[property.Name] = property.Value
+	}
+	return values
+}
+
+func parseTMX(dir string, data []byte) (*tiledMap, error) {
+	var raw tmxMap
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	m := &tiledMap{
+		Width:      raw.Width,
+		Height:     raw.Height,
+		Properties: tmxProperties(raw.Properties),
+	}
+	for _, layer := range raw.Layers {
+		if layer.Data.Encoding != "csv" {
+			return nil, fmt.Errorf("tile layer: only csv encoding is supported, not %q", layer.Data.Encoding)
+		}
+
+		var tiles []uint32
+		for _, field := range strings.Split(layer.Data.Text, ",") {
+			gid, err := strconv.ParseUint(strings.TrimSpace(field), 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("tile layer: %w", err)
+			}
+			tiles = append(tiles, uint32(gid))
+		}
+		m.TileLayers = append(m.TileLayers, tiles)
+	}
+
+	for _, group := range raw.Groups {
+		for _, object := range group.Objects {
+			class := object.Class
+			if class == "" {
+				class = object.Type
+			}
+			m.Objects = append(m.Objects, tiledObject{
+				ID:         object.ID,
+				Name:       object.Name,
+				Class:      class,
+				X:          object.X,
+				Y:          object.Y,
+				Width:      object.Width,
+				Height:     object.Height,
+				GID:        object.GID,
+				Properties: tmxProperties(object.Properties),
+			})
+		}
+	}
+
+	for _, ref := range raw.Tilesets {
+		if ref.Source != "" {
+			t, err := loadTileset(dir, ref.Source, ref.FirstGID)
+			if err != nil {
+				return nil, err
+			}
+			m.Tilesets = append(m.Tilesets, t)
+			continue
+		}
+
+		t, err := ref.tileset(dir, ref.FirstGID)
+		if err != nil {
+			return nil, err
+		}
+		m.Tilesets = append(m.Tilesets, t)
+	}
+
+	return m, nil
+}
+
+// loadTileset reads an external tileset, in XML when its file ends with
+// .tsx and in JSON otherwise. Its images are relative to its own file.
+func loadTileset(dir, source string, firstGID uint32) (tiledTileset, error) {
+	file := path.Join(dir, source)
+	data, err := readFile(file)
+	if err != nil {
+		return tiledTileset{}, err
+	}
+
+	var t tiledTileset
+	if path.Ext(file) == ".tsx" {
+		var raw tmxTileset
+		if err = xml.Unmarshal(data, &raw); err == nil {
+			t, err = raw.tileset(path.Dir(file), firstGID)
+		}
+	} else {
+		var raw tmjTileset
+		if err = json.Unmarshal(data, &raw); err == nil {
+			t, err = raw.tileset(path.Dir(file), firstGID)
+		}
+	}
+	if err != nil {
+		return tiledTileset{}, fmt.Errorf("tileset %s: %w", source, err)
+	}
+	return t, nil
+}
+
+func (raw *tmxTileset) tileset(dir string, firstGID uint32) (tiledTileset, error) {
+	if raw.Image != nil {
+		return tiledTileset{}, fmt.Errorf("only image collections are supported")
+	}
+
+	t := tiledTileset{
+		FirstGID:   firstGID,
+		Sprites:    map[uint32]string{},
+		Properties: map[uint32]map[string]string{},
+	}
+	for _, tile := range raw.Tiles {
+		t.Sprites[tile.ID] = spriteName(path.Join(dir, tile.Image.Source))
+		t.Properties[tile.ID] = tmxProperties(tile.Properties)
+	}
+	return t, nil
+}
@@ -0,0 +1,208 @@
+package internal
+
+import (
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// The maps below are the same in both formats: two layers with a flipped
+// tile, a tileset overriding what is solid, a tile object, a spawn point,
+// a chest and a spawn table.
+
+const testTMJ = `{
+ "width": 2,
+ "height": 2,
+ "properties": [
+  {"name": "monsters", "type": "int", "value": 3},
+  {"name": "spawn_goblin", "type": "int", "value": 2},
+  {"name": "patrol_goblin", "type": "bool", "value": true},
+  {"name": "spawn_imp", "type": "int", "value": 1}
+ ],
+ "layers": [
+  {"type": "tilelayer", "data": [1, 2147483650, 1, 1]},
+  {"type": "tilelayer", "data": [0, 3, 0, 0]},
+  {"type": "objectgroup", "objects": [
+   {"id": 1, "type": "spawn", "x": 0, "y": 0, "width": 16, "height": 16},
+   {"id": 2, "class": "decoration", "gid": 3, "x": 16, "y": 32, "width": 16, "height": 16},
+   {"id": 3, "name": "chest", "class": "chest", "x": 0, "y": 16, "width": 16, "height": 16}
+  ]}
+ ],
+ "tilesets": [
+  {"firstgid": 1, "tiles": [
+   {"id": 0, "image": "../sprites/floor_1.png"},
+   {"id": 1, "image": "../sprites/wall_mid.png", "properties": [{"name": "solid", "type": "bool", "value": false}]},
+   {"id": 2, "image": "../sprites/column.png", "properties": [{"name": "solid", "type": "bool", "value": true}]}
+  ]}
+ ]
+}`
+
+const testTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<map width="2" height="2">
+ <properties>
+  <property name="monsters" type="int" value="3"/>
+  <property name="spawn_goblin" type="int" value="2"/>
+  <property name="patrol_goblin" type="bool" value="true"/>
+  <property name="spawn_imp" type="int" value="1"/>
+ </properties>
+ <tileset firstgid="1">
+  <tile id="0"><image source="../sprites/floor_1.png"/></tile>
+  <tile id="1">
+   <properties><property name="solid" type="bool" value="false"/></properties>
+   <image source="../sprites/wall_mid.png"/>
+  </tile>
+  <tile id="2">
+   <properties><property name="solid" type="bool" value="true"/></properties>
+   <image source="../sprites/column.png"/>
+  </tile>
+ </tileset>
+ <layer><data encoding="csv">
+1,2147483650,
+1,1
+</data></layer>
+ <layer><data encoding="csv">
+0,3,
+0,0
+</data></layer>
+ <objectgroup>
+  <object id="1" type="spawn" x="0" y="0" width="16" height="16"/>
+  <object id="2" class="decoration" gid="3" x="16" y="32" width="16" height="16"/>
+  <object id="3" name="chest" class="chest" x="0" y="16" width="16" height="16"/>
+ </objectgroup>
+</map>`
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(dir string, data []byte) (*tiledMap, error)
+		data  string
+	}{
+		{"tmj", parseTMJ, testTMJ},
+		{"tmx", parseTMX, testTMX},
+	}
+
+	want := &Level{
+		Name: "test",
+		Layers: [][][]string{
+			{{"floor_1", "wall_mid"}, {"floor_1", "floor_1"}},
+			{{"", "column"}, {"", ""}},
+		},
+		Solids: map[string]bool{"wall_mid": false, "column": true},
+		Spawns: []image.Point{{8, 8}},
+		SpawnTable: SpawnTable{
+			Count: 3,
+			Entries: []SpawnEntry{
+				{Skin: "goblin", Weight: 2, Patrol: true},
+				{Skin: "imp", Weight: 1},
+			},
+		},
+		Objects: []*Object{
+			{Id: "object_2", Kind: ObjectDecoration, X: 24, Y: 24, Width: 16, Height: 16, State: "column"},
+			{Id: "chest", Kind: ObjectChest, X: 8, Y: 24, Width: 16, Height: 16, State: ChestClosed},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			level, err := parseLevel("test", "asset/levels", []byte(test.data), test.parse)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(level.Layers, want.Layers) {
+				t.Errorf("layers %v, want %v", level.Layers, want.Layers)
+			}
+			if !reflect.DeepEqual(level.Solids, want.Solids) {
+				t.Errorf("solids %v, want %v", level.Solids, want.Solids)
+			}
+			if !level.Solid(1, 0) || level.Solid(0, 0) {
+				t.Error("the column should be solid and the floor not")
+			}
+			if !reflect.DeepEqual(level.Spawns, want.Spawns) {
+				t.Errorf("spawns %v, want %v", level.Spawns, want.Spawns)
+			}
+			if !reflect.DeepEqual(level.SpawnTable, want.SpawnTable) {
+				t.Errorf("spawn table %+v, want %+v", level.SpawnTable, want.SpawnTable)
+			}
+			if len(level.Objects) != len(want.Objects) {
+				t.Fatalf("%d objects, want %d", len(level.Objects), len(want.Objects))
+			}
+			for i, object := range level.Objects {
+				if !proto.Equal(object, want.Objects[i]) {
+					t.Errorf("object %v, want %v", object, want.Objects[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseLevelErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(dir string, data []byte) (*tiledMap, error)
+		data  string
+		want  string
+	}{
+		{
+			name:  "tmj unknown class",
+			parse: parseTMJ,
+			data:  strings.Replace(testTMJ, `"class": "chest"`, `"class": "altar"`, 1),
+			want:  `unknown class "altar"`,
+		},
+		{
+			name:  "tmx unknown class",
+			parse: parseTMX,
+			data:  strings.Replace(testTMX, `class="chest"`, `class="altar"`, 1),
+			want:  `unknown class "altar"`,
+		},
+		{
+			name:  "tmj compressed layer",
+			parse: parseTMJ,
+			data: strings.Replace(testTMJ, `"data": [0, 3, 0, 0]`,
+				`"data": "eJxjYGBgYAQAAA0AAw==", "encoding": "base64", "compression": "zlib"`, 1),
+			want: "only uncompressed arrays are supported",
+		},
+		{
+			name:  "tmx base64 layer",
+			parse: parseTMX,
+			data:  strings.Replace(testTMX, "<data encoding=\"csv\">\n0,3,\n0,0\n", `<data encoding="base64" compression="zlib">eJxjYGBgYAQAAA0AAw==`, 1),
+			want:  `only csv encoding is supported, not "base64"`,
+		},
+		{
+			name:  "tmx xml layer",
+			parse: parseTMX,
+			data:  strings.Replace(testTMX, "<data encoding=\"csv\">\n0,3,\n0,0\n", `<data><tile gid="0"/><tile gid="3"/><tile/><tile/>`, 1),
+			want:  `only csv encoding is supported, not ""`,
+		},
+		{
+			name:  "tmj missing tile",
+			parse: parseTMJ,
+			data:  strings.Replace(testTMJ, `[0, 3, 0, 0]`, `[0, 4, 0, 0]`, 1),
+			want:  "tile 4 has no image",
+		},
+		{
+			name:  "tmx short layer",
+			parse: parseTMX,
+			data:  strings.Replace(testTMX, "0,3,\n0,0\n", "0,3\n", 1),
+			want:  "tile layer has 2 tiles, want 4",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.data == testTMJ || test.data == testTMX {
+				t.Fatal("the map was not changed")
+			}
+			_, err := parseLevel("test", "asset/levels", []byte(test.data), test.parse)
+			if err == nil {
+				t.Fatalf("no error, want %q", test.want)
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("error %q, want %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package internal
+
+import "math"
+
+// Weapon describes how a melee weapon hits.
+type Weapon struct {
+	// Damage is taken by every unit the swing hits.
+	Damage int32
+	// Reach is how far from the center of the attacker the blade gets,
+	// and Arc the angle in radians it sweeps around the aim.
+	Reach float64
+	Arc   float64
+	// Cooldown is the number of ticks before the next attack.
+	Cooldown uint32
+	// Knockback is the speed a hit unit of mass 1 is pushed away with.
+	Knockback float64
+}
+
+// Weapons are the weapons by name. Most have a weapon_<name> sprite.
+var Weapons = map[string]Weapon{
+	"knife":         {Damage: 1, Reach: 14, Arc: math.Pi / 2, Cooldown: 15, Knockback: 1.5},
+	"regular_sword": {Damage: 2, Reach: 20, Arc: math.Pi * 2 / 3, Cooldown: 24, Knockback: 2},
+	"katana":        {Damage: 2, Reach: 26, Arc: math.Pi * 2 / 3, Cooldown: 20, Knockback: 2},
+	"axe":           {Damage: 3, Reach: 20, Arc: math.Pi * 2 / 3, Cooldown: 32, Knockback: 3},
+	"spear":         {Damage: 2, Reach: 30, Arc: math.Pi / 4, Cooldown: 28, Knockback: 2.5},
+	"big_hammer":    {Damage: 4, Reach: 32, Arc: math.Pi, Cooldown: 45, Knockback: 4},
+
+	"rusty_sword":       {Damage: 1, Reach: 18, Arc: math.Pi / 2, Cooldown: 40, Knockback: 1.5},
+	"cleaver":           {Damage: 2, Reach: 16, Arc: math.Pi / 2, Cooldown: 45, Knockback: 2},
+	"baton_with_spikes": {Damage: 2, Reach: 18, Arc: math.Pi / 2, Cooldown: 40, Knockback: 2.5},
+	"red_magic_staff":   {Damage: 1, Reach: 20, Arc: math.Pi / 3, Cooldown: 50, Knockback: 1},
+	"mace":              {Damage: 3, Reach: 22, Arc: math.Pi * 2 / 3, Cooldown: 70, Knockback: 4},
+
+	// Mimics bite; there is no sprite to draw.
+	"bite": {Damage: 2, Reach: 14, Arc: math.Pi / 2, Cooldown: 40, Knockback: 3},
+}
+
+// PlayerWeapons are the weapons players are randomly given.
+var PlayerWeapons = []string{"knife", "regular_sword", "katana", "axe", "spear", "big_hammer"}
+
+// SwingTicks is how long a swing is drawn for.
+const SwingTicks = 12
+
+// stunTicks is how long a hit unit staggers and cannot walk.
+const stunTicks = 15
+
+// knockbackDecay slows a pushed unit down every tick.
+const knockbackDecay = 0.8
+
+// attack starts a swing of the unit's weapon towards where it is heading,
+// or where it faces when standing still. Only the server decides what the
+// swing hits; replicas just show it.
+func (world *World) attack(unit *Unit) {
+	weapon, ok := Weapons[unit.Weapon]
+	if !ok || unit.Cooldown > 0 || unit.Stun > 0 || dead(unit) {
+		return
+	}
+
+	unit.Swing = SwingTicks
+	unit.Cooldown = weapon.Cooldown
+	unit.AimX, unit.AimY = normalize(unit.MoveX, unit.MoveY)
+	if unit.AimX == 0 && unit.AimY == 0 {
+		unit.AimX = 1
+		if unit.Side == Direction_left {
+			unit.AimX = -1
+		}
+	}
+	if world.Replica {
+		return
+	}
+
+	x, y := world.center(unit)
+	aim := math.Hypot(unit.AimX, unit.AimY)
+	for _, id := range sortedIDs(world.Units) {
+		target := world.Units[id]
+		if target == unit || dead(target) || world.allies(unit, target) {
+			continue
+		}
+
+		tx, ty := world.center(target)
+		dx, dy := tx-x, ty-y
+		distance := math.Hypot(dx, dy)
+		if distance > weapon.Reach+target.Radius {
+			continue
+		}
+		// Units standing on the attacker are always hit.
+		if distance > 0 && (dx*unit.AimX+dy*unit.AimY)/(distance*aim) < math.Cos(weapon.Arc/2) {
+			continue
+		}
+
+		world.hit(unit, target, weapon, dx, dy)
+	}
+}
+
+// hit staggers the target, pushes it away along dx, dy and deals the damage
+// of the weapon. Heavy units are pushed less.
+func (world *World) hit(attacker, target *Unit, weapon Weapon, dx, dy float64) {
+	target.Stun = stunTicks
+	target.Action = UnitActionHit
+
+	distance := math.Hypot(dx, dy)
+	if distance == 0 {
+		dx, distance = 1, 1
+	}
+	mass := target.Mass
+	if mass <= 0 {
+		mass = 1
+	}
+	target.PushX = dx / distance * weapon.Knockback / mass
+	target.PushY = dy / distance * weapon.Knockback / mass
+	world.damage(target, weapon.Damage, attacker.Id)
+}
+
+// recover counts down the combat timers of the unit by a single tick and
+// moves it along its knockback. Knockback and respawns are only simulated
+// by the server.
+func (world *World) recover(unit *Unit) {
+	if dead(unit) {
+		if !world.Replica {
+			unit.Respawn--
+			if unit.Respawn == 0 {
+				world.respawn(unit)
+			}
+		}
+		return
+	}
+
+	if unit.Cooldown > 0 {
+		unit.Cooldown--
+	}
+	if unit.Boost > 0 {
+		unit.Boost--
+	}
+	if unit.Shield > 0 {
+		unit.Shield--
+	}
+	if unit.Swing > 0 {
+		unit.Swing--
+	}
+	if unit.Stun == 0 {
+		return
+	}
+
+	if !world.Replica {
+		world.move(unit, unit.PushX, unit.PushY)
+		unit.PushX *= knockbackDecay
+		unit.PushY *= knockbackDecay
+	}
+	unit.Stun--
+	if unit.Stun == 0 {
+		unit.PushX, unit.PushY = 0, 0
+		unit.Action = action(unit)
+	}
+}
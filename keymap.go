@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+
+	e "github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Action is something the player can do with a key.
+type Action string
+
+const (
+	ActionMoveLeft  Action = "move_left"
+	ActionMoveRight Action = "move_right"
+	ActionMoveUp    Action = "move_up"
+	ActionMoveDown  Action = "move_down"
+	ActionAttack    Action = "attack"
+	ActionInteract  Action = "interact"
+	ActionUse1      Action = "use_1"
+	ActionUse2      Action = "use_2"
+	ActionUse3      Action = "use_3"
+	ActionUse4      Action = "use_4"
+	ActionUse5      Action = "use_5"
+	ActionSettings  Action = "settings"
+)
+
+// useActions are the actions that use the inventory slots, in order.
+var useActions = []Action{ActionUse1, ActionUse2, ActionUse3, ActionUse4, ActionUse5}
+
+// actions lists the actions in the order the settings screen shows them.
+var actions = []struct {
+	action Action
+	label  string
+}{
+	{ActionMoveLeft, "Move left"},
+	{ActionMoveRight, "Move right"},
+	{ActionMoveUp, "Move up"},
+	{ActionMoveDown, "Move down"},
+	{ActionAttack, "Attack"},
+	{ActionInteract, "Interact"},
+	{ActionUse1, "Use slot 1"},
+	{ActionUse2, "Use slot 2"},
+	{ActionUse3, "Use slot 3"},
+	{ActionUse4, "Use slot 4"},
+	{ActionUse5, "Use slot 5"},
+	{ActionSettings, "Settings"},
+}
+
+// Keymap binds every action to the keys that trigger it. The first key of
+// an action is the one players rebind; the others are alternatives.
+type Keymap map[Action][]e.Key
+
+func defaultKeymap() Keymap {
+	return Keymap{
+		ActionMoveLeft:  {e.KeyA, e.KeyLeft},
+		ActionMoveRight: {e.KeyD, e.KeyRight},
+		ActionMoveUp:    {e.KeyW, e.KeyUp},
+		ActionMoveDown:  {e.KeyS, e.KeyDown},
+		ActionAttack:    {e.KeySpace},
+		ActionInteract:  {e.KeyE},
+		ActionUse1:      {e.KeyDigit1},
+		ActionUse2:      {e.KeyDigit2},
+		ActionUse3:      {e.KeyDigit3},
+		ActionUse4:      {e.KeyDigit4},
+		ActionUse5:      {e.KeyDigit5},
+		ActionSettings:  {e.KeyEscape},
+	}
+}
+
+// Pressed reports whether a key bound to the action is held down.
+func (keymap Keymap) Pressed(action Action) bool {
+	for _, key := range keymap[action] {
+		if e.IsKeyPressed(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// JustPressed reports whether a key bound to the action went down in this
+// tick.
+func (keymap Keymap) JustPressed(action Action) bool {
+	for _, key := range keymap[action] {
+		if inpututil.IsKeyJustPressed(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Bind makes key the main key of the action. The key is taken away from
+// any other action, so one key never does two things.
+func (keymap Keymap) Bind(action Action, key e.Key) {
+	// The alternatives of the action are its keys after the main one, read
+	// before key is taken away from them.
+	keys := []e.Key{key}
+	if old := keymap[action]; len(old) > 1 {
+		for _, k := range old[1:] {
+			if k != key {
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	keymap.unbind(key)
+	keymap[action] = keys
+}
+
+// Reset restores the default keys of the action, taking them away from
+// any other action like Bind.
+func (keymap Keymap) Reset(action Action) {
+	keys := defaultKeymap()[action]
+	for _, key := range keys {
+		keymap.unbind(key)
+	}
+	keymap[action] = keys
+}
+
+// unbind takes the key away from every action.
+func (keymap Keymap) unbind(key e.Key) {
+	for action, bound := range keymap {
+		kept := bound[:0]
+		for _, k := range bound {
+			if k != key {
+				kept = append(kept, k)
+			}
+		}
+		keymap[action] = kept
+	}
+}
+
+// loadKeymap reads the keymap saved by the player. Actions the saved
+// keymap does not know get their default keys. A key saved for several
+// actions only stays with the first of them, in the order of actions.
+func loadKeymap() Keymap {
+	keymap := defaultKeymap()
+
+	data, err := readKeymap()
+	if errors.Is(err, os.ErrNotExist) {
+		return keymap
+	}
+	if err != nil {
+		log.Println(err)
+		return keymap
+	}
+
+	saved := Keymap{}
+	err = json.Unmarshal(data, &saved)
+	if err != nil {
+		log.Println(err)
+		return keymap
+	}
+	claimed := map[e.Key]bool{}
+	for _, entry := range actions {
+		keys, ok := saved[entry.action]
+		if !ok {
+			continue
+		}
+
+		bound := []e.Key{}
+		for _, key := range keys {
+			if claimed[key] {
+				continue
+			}
+			claimed[key] = true
+			keymap.unbind(key)
+			bound = append(bound, key)
+		}
+		keymap[entry.action] = bound
+	}
+
+	return keymap
+}
+
+func saveKeymap(keymap Keymap) {
+	data, err := json.Marshal(keymap)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	err = writeKeymap(data)
+	if err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,38 @@
+//go:build !js
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// keymapPath returns where the keymap is kept on desktops, next to the
+// settings of other applications.
+func keymapPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "another-hero", "keymap.json"), nil
+}
+
+func readKeymap() ([]byte, error) {
+	path, err := keymapPath()
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+func writeKeymap(data []byte) error {
+	path, err := keymapPath()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,24 @@
+//go:build js
+
+package main
+
+import (
+	"os"
+	"syscall/js"
+)
+
+// keymapItem is the localStorage key the keymap is kept under in browsers.
+const keymapItem = "another-hero/keymap"
+
+func readKeymap() ([]byte, error) {
+	item := js.Global().Get("localStorage").Call("getItem", keymapItem)
+	if item.IsNull() {
+		return nil, os.ErrNotExist
+	}
+	return []byte(item.String()), nil
+}
+
+func writeKeymap(data []byte) error {
+	js.Global().Get("localStorage").Call("setItem", keymapItem, string(data))
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"image"
 	"log"
+	"math"
+	"net/url"
 	"os"
 	"sort"
 	"time"
@@ -30,6 +32,15 @@ type Sprite struct {
 	Y      float64
 	Side   internal.Direction
 	Config image.Config
+	Hit    bool
+	Shield bool
+	// Flat sprites lie on the floor, under everything else.
+	Flat bool
+
+	Weapon string
+	Swing  uint32
+	AimX   float64
+	AimY   float64
 }
 
 type Camera struct {
@@ -43,20 +54,50 @@ var world *internal.World
 var camera *Camera
 var frames map[string]internal.Frames
 var frame int
-var lastKey e.Key
-var prevKey e.Key
+var keymap Keymap
+var sender *InputSender
 var levelImage *e.Image
+var shownLevel *internal.Level
 
 // Game implements ebiten.Game interface.
 type Game struct {
 	Conn *websocket.Conn
+
+	width  int
+	height int
 }
 
 // Update proceeds the game state.
 // Update is called every tick (1/60 [s] by default).
 func (g *Game) Update() error {
 	// Write your game's logical update.
-	handleKeyboard(g.Conn)
+	handleGamepads()
+	touch.Update(g.width, g.height)
+	settings.Update()
+	handleInput()
+	world.Step(world.Tick + 1)
+	// The level is only known once the server has told which it is, and
+	// changes whenever the unit goes to another one.
+	if world.Level != nil && world.Level != shownLevel {
+		img, err := prepareLevelImage(world.Level)
+		if err != nil {
+			return err
+		}
+		levelImage, shownLevel = img, world.Level
+		// Start over looking at the unit where it arrived.
+		camera = nil
+	}
+	if tick, ok := world.Acknowledgement(); ok && world.Level != nil {
+		acknowledge(g.Conn, tick)
+	}
+
+	if me := world.Units[world.MyID]; camera == nil && me != nil {
+		camera = &Camera{
+			X:       me.X,
+			Y:       me.Y,
+			Padding: 30,
+		}
+	}
 
 	return nil
 }
@@ -74,18 +115,59 @@ func (g *Game) Draw(screen *e.Image) {
 
 	frame++
 
+	now := time.Now()
 	var sprites []Sprite
-	for _, unit := range world.Units {
+	for _, unit := range world.Snapshot().Units {
+		if unit.Action == internal.UnitActionDead {
+			continue
+		}
+		x, y := world.Position(unit, now)
+		sprite := unitFrames(unit)
 		sprites = append(sprites, Sprite{
-			Frames: frames[unit.Skin+"_"+unit.Action].Frames,
+			Frames: sprite.Frames,
 			Frame:  int(unit.Frame),
-			X:      unit.X,
-			Y:      unit.Y,
+			X:      x,
+			Y:      y,
 			Side:   unit.Side,
-			Config: frames[unit.Skin+"_"+unit.Action].Config,
+			Config: sprite.Config,
+			Hit:    unit.Action == internal.UnitActionHit,
+			Shield: unit.Shield > 0,
+			Weapon: unit.Weapon,
+			Swing:  unit.Swing,
+			AimX:   unit.AimX,
+			AimY:   unit.AimY,
+		})
+	}
+	for _, item := range world.Snapshot().Items {
+		sprite := frames[item.Kind]
+		sprites = append(sprites, Sprite{
+			Frames: sprite.Frames,
+			X:      item.X - float64(sprite.Config.Width)/2,
+			Y:      item.Y - float64(sprite.Config.Height)/2,
+			Side:   internal.Direction_right,
+			Config: sprite.Config,
+		})
+	}
+	for _, object := range world.Snapshot().Objects {
+		img, cfg, ok := objectFrame(object)
+		if !ok {
+			continue
+		}
+		// Objects stand on the bottom of the area they cover.
+		_, _, _, bottom := internal.ObjectBox(object)
+		sprites = append(sprites, Sprite{
+			Frames: []image.Image{img},
+			X:      object.X - float64(cfg.Width)/2,
+			Y:      bottom - float64(cfg.Height),
+			Side:   internal.Direction_right,
+			Config: cfg,
+			Flat:   flatObjects[object.Kind],
 		})
 	}
 	sort.Slice(sprites, func(i, j int) bool {
+		if sprites[i].Flat != sprites[j].Flat {
+			return sprites[i].Flat
+		}
 		depth1 := sprites[i].Y + float64(sprites[i].Config.Height)
 		depth2 := sprites[j].Y + float64(sprites[j].Config.Height)
 		return depth1 < depth2
@@ -100,17 +182,28 @@ func (g *Game) Draw(screen *e.Image) {
 		}
 
 		op.GeoM.Translate(sprite.X-camera.X, sprite.Y-camera.Y)
+		if sprite.Hit {
+			op.ColorScale.Scale(1, 0.4, 0.4, 1)
+		} else if sprite.Shield {
+			op.ColorScale.Scale(1, 1, 0.5, 1)
+		}
 
-		img := e.NewImageFromImage(sprite.Frames[(frame/7+sprite.Frame)%4])
+		img := e.NewImageFromImage(sprite.Frames[(frame/7+sprite.Frame)%len(sprite.Frames)])
 		screen.DrawImage(img, op)
+		drawWeapon(screen, sprite)
 	}
 
+	drawHUD(screen)
+	touch.Draw(screen)
+	settings.Draw(screen)
+
 	ebitenutil.DebugPrint(screen, fmt.Sprintf("TPS: %0.2f", e.CurrentTPS()))
 }
 
 // Layout takes the outside size (e.g., the window size) and returns the (logical) screen size.
 // If you don't have to adjust the screen size with the outside size, just return a fixed size.
 func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	g.width, g.height = outsideWidth, outsideHeight
 	return outsideWidth, outsideHeight
 }
 
@@ -126,27 +219,39 @@ func init() {
 		Units:   map[string]*internal.Unit{},
 	}
 
+	keymap = loadKeymap()
+
 	var err error
 	frames, err = internal.LoadResources()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	levelImage, err = prepareLevelImage()
+	world.Footprints, err = internal.LoadFootprints()
 	if err != nil {
 		log.Fatal(err)
 	}
 }
 
 func main() {
-	go world.Evolve()
-
 	APP_IP := getEnv("APP_IP", "webgame.na4u.ru")
 	APP_PORT := getEnv("APP_PORT", "443")
+	APP_INTERPOLATION_DELAY := getEnv("APP_INTERPOLATION_DELAY", internal.DefaultInterpolationDelay.String())
+
+	delay, err := time.ParseDuration(APP_INTERPOLATION_DELAY)
+	if err != nil {
+		log.Fatal(err)
+	}
+	world.InterpolationDelay = delay
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	c, _, err := websocket.Dial(ctx, fmt.Sprintf("wss://%s:%s/ws", APP_IP, APP_PORT), nil)
+	address := fmt.Sprintf("wss://%s:%s/ws", APP_IP, APP_PORT)
+	if room := roomCode(); room != "" {
+		address += "?room=" + url.QueryEscape(room)
+	}
+	c, _, err := websocket.Dial(ctx, address, nil)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -168,54 +273,170 @@ func main() {
 			}
 
 			world.HandleEvent(event)
-
-			if event.Type == internal.Event_type_connect {
-				me := world.Units[world.MyID]
-				camera = &Camera{
-					X:       me.X,
-					Y:       me.Y,
-					Padding: 30,
-				}
-			}
 		}
 	}(c)
 
 	e.SetRunnableOnUnfocused(true)
 	e.SetWindowSize(config.width, config.height)
 	e.SetWindowTitle(config.title)
+	sender = &InputSender{conn: c}
 	game := &Game{Conn: c}
 	if err := e.RunGame(game); err != nil {
 		log.Fatal(err)
 	}
 }
 
-func prepareLevelImage() (*e.Image, error) {
-	tileSize := 16
-	level := internal.LoadLevel()
-	width := len(level[0])
-	height := len(level)
+func prepareLevelImage(level *internal.Level) (*e.Image, error) {
+	tileSize := internal.TileSize
+	width := level.Width()
+	height := level.Height()
 	levelImage := e.NewImage(width*tileSize, height*tileSize)
 
-	for i := 0; i < width; i++ {
-		for j := 0; j < height; j++ {
-			op := &e.DrawImageOptions{}
-			op.GeoM.Translate(float64(i*tileSize), float64(j*tileSize))
+	for _, layer := range level.Layers {
+		for i := 0; i < width; i++ {
+			for j := 0; j < height; j++ {
+				sprite, ok := frames[layer[j][i]]
+				if !ok {
+					if layer[j][i] != "" {
+						return nil, fmt.Errorf("level %s: no sprite %q", level.Name, layer[j][i])
+					}
+					continue
+				}
+
+				op := &e.DrawImageOptions{}
+				op.GeoM.Translate(float64(i*tileSize), float64(j*tileSize))
 
-			img := e.NewImageFromImage(frames[level[j][i]].Frames[0])
-			levelImage.DrawImage(img, op)
+				img := e.NewImageFromImage(sprite.Frames[0])
+				levelImage.DrawImage(img, op)
+			}
 		}
 	}
 
 	return levelImage, nil
 }
 
+// unitFrames returns the animation of what the unit is doing. Skins without
+// a sprite for an action stand idle.
+func unitFrames(unit *internal.Unit) internal.Frames {
+	if sprite, ok := frames[unit.Skin+"_"+unit.Action]; ok {
+		return sprite
+	}
+	return frames[unit.Skin+"_"+internal.UnitActionIdle]
+}
+
+// flatObjects are the kinds of objects that are drawn under units.
+var flatObjects = map[string]bool{
+	internal.ObjectSpikes:     true,
+	internal.ObjectPit:        true,
+	internal.ObjectLadder:     true,
+	internal.ObjectDecoration: true,
+}
+
+// objectFrame returns the picture of an object in its current state. While
+// the object plays an animation its timer tells how far along it is.
+func objectFrame(object *internal.Object) (image.Image, image.Config, bool) {
+	switch object.Kind {
+	case internal.ObjectDoor:
+		sprite, ok := frames["doors_leaf_"+object.State]
+		return firstFrame(sprite, ok)
+
+	case internal.ObjectSpikes:
+		sprite, ok := frames["floor_spikes"]
+		if !ok {
+			return nil, image.Config{}, false
+		}
+		// The spikes shoot up a frame at a time and stay up.
+		i := 0
+		if object.State == internal.SpikesUp {
+			i = min(len(sprite.Frames)-1, 1+(internal.SpikesUpTicks-int(object.Timer))/4)
+		}
+		return sprite.Frames[i], sprite.Config, true
+
+	case internal.ObjectPit:
+		sprite, ok := frames["hole"]
+		return firstFrame(sprite, ok)
+
+	case internal.ObjectLadder:
+		sprite, ok := frames["floor_ladder"]
+		return firstFrame(sprite, ok)
+
+	case internal.ObjectDecoration:
+		sprite, ok := frames[object.State]
+		if !ok {
+			return nil, image.Config{}, false
+		}
+		return sprite.Frames[(frame/7)%len(sprite.Frames)], sprite.Config, true
+
+	case internal.ObjectChest:
+		state := object.State
+		if state == internal.ChestClosed {
+			state = internal.ChestFull
+		}
+		sprite, ok := frames["chest_"+state+"_open"]
+		if !ok {
+			return nil, image.Config{}, false
+		}
+
+		last := len(sprite.Frames) - 1
+		i := last
+		if object.State == internal.ChestClosed {
+			i = 0
+		} else if object.Timer > 0 {
+			i = (internal.ChestOpenTicks - int(object.Timer)) * last / internal.ChestOpenTicks
+		}
+		return sprite.Frames[i], sprite.Config, true
+	}
+
+	return nil, image.Config{}, false
+}
+
+func firstFrame(sprite internal.Frames, ok bool) (image.Image, image.Config, bool) {
+	if !ok {
+		return nil, image.Config{}, false
+	}
+	return sprite.Frames[0], sprite.Config, true
+}
+
+// drawWeapon draws the weapon in the hand of the unit. At rest it is held
+// up on the side the unit faces; during a swing it sweeps across the arc
+// of the weapon around the aim.
+func drawWeapon(screen *e.Image, sprite Sprite) {
+	weapon, ok := internal.Weapons[sprite.Weapon]
+	img, found := frames["weapon_"+sprite.Weapon]
+	if !ok || !found {
+		return
+	}
+
+	// The sprites point up with the grip at the bottom.
+	angle := 0.3
+	if sprite.Side == internal.Direction_left {
+		angle = -angle
+	}
+	if sprite.Swing > 0 {
+		progress := 1 - float64(sprite.Swing)/internal.SwingTicks
+		angle = math.Atan2(sprite.AimY, sprite.AimX) + math.Pi/2 - weapon.Arc/2 + weapon.Arc*progress
+	}
+
+	op := &e.DrawImageOptions{}
+	op.GeoM.Translate(-float64(img.Config.Width)/2, -float64(img.Config.Height))
+	op.GeoM.Rotate(angle)
+	op.GeoM.Translate(
+		sprite.X+float64(sprite.Config.Width)/2-camera.X,
+		sprite.Y+float64(sprite.Config.Height)*2/3-camera.Y,
+	)
+	screen.DrawImage(e.NewImageFromImage(img.Frames[0]), op)
+}
+
 func handleCamera(screen *e.Image) {
 	if camera == nil {
 		return
 	}
 
-	player := world.Units[world.MyID]
-	frame := frames[player.Skin+"_"+player.Action]
+	player := world.Snapshot().Units[world.MyID]
+	if player == nil {
+		return
+	}
+	frame := unitFrames(player)
 	camera.X = player.X - float64(config.width-frame.Config.Width)/2
 	camera.Y = player.Y - float64(config.height-frame.Config.Height)/2
 
@@ -224,110 +445,72 @@ func handleCamera(screen *e.Image) {
 	screen.DrawImage(levelImage, op)
 }
 
-func handleKeyboard(c *websocket.Conn) {
-	event := &internal.Event{}
-
-	if world.MyID == "" || world.Units[world.MyID] == nil {
-		return
+func readKeyboard(intent *Intent) {
+	// Opposite keys cancel out; the server normalizes diagonals.
+	x, y := 0.0, 0.0
+	if keymap.Pressed(ActionMoveLeft) {
+		x--
 	}
-
-	if e.IsKeyPressed(e.KeyA) || e.IsKeyPressed(e.KeyLeft) {
-		event = &internal.Event{
-			Type: internal.Event_type_move,
-			Data: &internal.Event_Move{
-				Move: &internal.EventMove{
-					PlayerId:  world.MyID,
-					Direction: internal.Direction_left,
-				},
-			},
-		}
-		if lastKey != e.KeyA {
-			lastKey = e.KeyA
-		}
+	if keymap.Pressed(ActionMoveRight) {
+		x++
+	}
+	if keymap.Pressed(ActionMoveUp) {
+		y--
 	}
+	if keymap.Pressed(ActionMoveDown) {
+		y++
+	}
+	intent.add(x, y)
 
-	if e.IsKeyPressed(e.KeyD) || e.IsKeyPressed(e.KeyRight) {
-		event = &internal.Event{
-			Type: internal.Event_type_move,
-			Data: &internal.Event_Move{
-				Move: &internal.EventMove{
-					PlayerId:  world.MyID,
-					Direction: internal.Direction_right,
-				},
-			},
-		}
-		if lastKey != e.KeyD {
-			lastKey = e.KeyD
+	if keymap.Pressed(ActionAttack) {
+		intent.Attack = true
+	}
+	if keymap.Pressed(ActionInteract) {
+		intent.Interact = true
+	}
+	for i, action := range useActions {
+		if keymap.Pressed(action) {
+			intent.Use = i + 1
 		}
 	}
+}
 
-	if e.IsKeyPressed(e.KeyW) || e.IsKeyPressed(e.KeyUp) {
-		event = &internal.Event{
-			Type: internal.Event_type_move,
-			Data: &internal.Event_Move{
-				Move: &internal.EventMove{
-					PlayerId:  world.MyID,
-					Direction: internal.Direction_up,
-				},
-			},
-		}
-		if lastKey != e.KeyW {
-			lastKey = e.KeyW
-		}
+func handleInput() {
+	if world.MyID == "" || world.Units[world.MyID] == nil {
+		return
 	}
 
-	if e.IsKeyPressed(e.KeyS) || e.IsKeyPressed(e.KeyDown) {
-		event = &internal.Event{
-			Type: internal.Event_type_move,
-			Data: &internal.Event_Move{
-				Move: &internal.EventMove{
-					PlayerId:  world.MyID,
-					Direction: internal.Direction_down,
-				},
-			},
-		}
-		if lastKey != e.KeyS {
-			lastKey = e.KeyS
-		}
+	// The settings screen takes the keyboard; the hero stands still.
+	intent := &Intent{}
+	if !settings.open {
+		readKeyboard(intent)
+		readGamepads(intent)
+		touch.read(intent)
 	}
+	sender.Update(*intent)
+}
 
-	unit := world.Units[world.MyID]
+// acknowledge tells the server which snapshot we have decoded, so the next
+// one can be sent as a delta against it.
+func acknowledge(c *websocket.Conn, tick uint64) {
+	send(c, &internal.Event{
+		Type: internal.Event_type_ack,
+		Data: &internal.Event_Ack{
+			Ack: &internal.EventAck{Tick: tick, Level: world.Level.Name},
+		},
+	})
+}
 
-	if event.Type == internal.Event_type_move {
-		if prevKey != lastKey {
-			message, err := proto.Marshal(event)
-			if err != nil {
-				log.Println(err)
-				return
-			}
-			err = c.Write(context.Background(), websocket.MessageBinary, message)
-			if err != nil {
-				log.Println(err)
-				return
-			}
-		}
-	} else {
-		if unit.Action != internal.UnitActionIdle {
-			event = &internal.Event{
-				Type: internal.Event_type_idle,
-				Data: &internal.Event_Idle{
-					Idle: &internal.EventIdle{PlayerId: world.MyID},
-				},
-			}
-			message, err := proto.Marshal(event)
-			if err != nil {
-				log.Println(err)
-				return
-			}
-			err = c.Write(context.Background(), websocket.MessageBinary, message)
-			if err != nil {
-				log.Println(err)
-				return
-			}
-			lastKey = -1
-		}
+func send(c *websocket.Conn, event *internal.Event) {
+	message, err := proto.Marshal(event)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	err = c.Write(context.Background(), websocket.MessageBinary, message)
+	if err != nil {
+		log.Println(err)
 	}
-	prevKey = lastKey
 }
 
 func getEnv(key, fallback string) string {
@@ -0,0 +1,9 @@
+//go:build !js
+
+package main
+
+// roomCode returns the code of the room to join from APP_ROOM, or nothing
+// to let the server pick one.
+func roomCode() string {
+	return getEnv("APP_ROOM", "")
+}
@@ -0,0 +1,17 @@
+//go:build js
+
+package main
+
+import "syscall/js"
+
+// roomCode returns the code of the room to join from the "room" parameter
+// of the page address, or nothing to let the server pick one.
+func roomCode() string {
+	search := js.Global().Get("location").Get("search")
+	params := js.Global().Get("URLSearchParams").New(search)
+	room := params.Call("get", "room")
+	if room.IsNull() {
+		return ""
+	}
+	return room.String()
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"example.com/game/internal"
+	"nhooyr.io/websocket"
+)
+
+// maxInputRate caps how many input messages are sent to the server per
+// second.
+const maxInputRate = 30
+
+// InputSender tells the server how the intent of the player changes. The
+// intent of every tick is compared with the last one sent, so holding keys
+// sends nothing, and changes that come faster than maxInputRate are held
+// back and folded into the next message, which carries the latest state.
+// Attacks, interactions and uses of items are sent once per press and go
+// before movement.
+type InputSender struct {
+	conn *websocket.Conn
+	sent Intent
+	wait int
+
+	attacking   bool
+	attack      bool
+	interacting bool
+	interact    bool
+	using       int
+	use         int
+}
+
+// Update sends the intent of this tick if it differs from what the server
+// knows and the rate allows it.
+func (s *InputSender) Update(intent Intent) {
+	if s.wait > 0 {
+		s.wait--
+	}
+	if intent.Attack && !s.attacking {
+		s.attack = true
+	}
+	s.attacking = intent.Attack
+	if intent.Interact && !s.interacting {
+		s.interact = true
+	}
+	s.interacting = intent.Interact
+	if intent.Use != 0 && intent.Use != s.using {
+		s.use = intent.Use
+	}
+	s.using = intent.Use
+	if s.wait > 0 {
+		return
+	}
+
+	if s.attack {
+		s.attack = false
+		s.dispatch(&internal.Event{
+			Type: internal.Event_type_attack,
+			Data: &internal.Event_Attack{
+				Attack: &internal.EventAttack{PlayerId: world.MyID},
+			},
+		})
+		return
+	}
+	if s.interact {
+		s.interact = false
+		s.dispatch(&internal.Event{
+			Type: internal.Event_type_interact,
+			Data: &internal.Event_Interact{
+				Interact: &internal.EventInteract{PlayerId: world.MyID},
+			},
+		})
+		return
+	}
+	if s.use != 0 {
+		s.dispatch(&internal.Event{
+			Type: internal.Event_type_use,
+			Data: &internal.Event_Use{
+				Use: &internal.EventUse{PlayerId: world.MyID, Slot: int32(s.use - 1)},
+			},
+		})
+		s.use = 0
+		return
+	}
+	if intent.X == s.sent.X && intent.Y == s.sent.Y {
+		return
+	}
+
+	event := &internal.Event{
+		Type: internal.Event_type_idle,
+		Data: &internal.Event_Idle{
+			Idle: &internal.EventIdle{PlayerId: world.MyID},
+		},
+	}
+	if intent.X != 0 || intent.Y != 0 {
+		event = &internal.Event{
+			Type: internal.Event_type_move,
+			Data: &internal.Event_Move{
+				Move: &internal.EventMove{
+					PlayerId: world.MyID,
+					X:        intent.X,
+					Y:        intent.Y,
+				},
+			},
+		}
+	}
+
+	s.dispatch(event)
+	s.sent = intent
+}
+
+// dispatch predicts the event locally and sends it to the server.
+func (s *InputSender) dispatch(event *internal.Event) {
+	world.Predict(event)
+	send(s.conn, event)
+	s.wait = internal.TickRate / maxInputRate
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	e "github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Settings is the in-game screen for rebinding keys. Its own navigation
+// keys are fixed, so a bad binding can always be undone.
+type Settings struct {
+	open      bool
+	selected  int
+	rebinding bool
+}
+
+var settings = &Settings{}
+
+// Update handles the keys of the settings screen. While it is open, the
+// game should not react to input.
+func (s *Settings) Update() {
+	if !s.open {
+		s.open = keymap.JustPressed(ActionSettings)
+		return
+	}
+
+	if s.rebinding {
+		keys := inpututil.AppendJustPressedKeys(nil)
+		if len(keys) == 0 {
+			return
+		}
+		if keys[0] != e.KeyEscape {
+			keymap.Bind(actions[s.selected].action, keys[0])
+			saveKeymap(keymap)
+		}
+		s.rebinding = false
+		return
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(e.KeyEscape):
+		s.open = false
+	case inpututil.IsKeyJustPressed(e.KeyUp):
+		s.selected = (s.selected + len(actions) - 1) % len(actions)
+	case inpututil.IsKeyJustPressed(e.KeyDown):
+		s.selected = (s.selected + 1) % len(actions)
+	case inpututil.IsKeyJustPressed(e.KeyEnter):
+		s.rebinding = true
+	case inpututil.IsKeyJustPressed(e.KeyBackspace):
+		keymap.Reset(actions[s.selected].action)
+		saveKeymap(keymap)
+	}
+}
+
+// Draw draws the settings screen over the game when it is open.
+func (s *Settings) Draw(screen *e.Image) {
+	if !s.open {
+		return
+	}
+
+	bounds := screen.Bounds()
+	vector.DrawFilledRect(screen, 0, 0, float32(bounds.Dx()), float32(bounds.Dy()), color.RGBA{0, 0, 0, 0xc0}, false)
+
+	x, y := 40, 40
+	ebitenutil.DebugPrintAt(screen, "SETTINGS", x, y)
+	y += 32
+	for i, entry := range actions {
+		cursor := "  "
+		if i == s.selected {
+			cursor = "> "
+		}
+
+		var names []string
+		for _, key := range keymap[entry.action] {
+			names = append(names, key.String())
+		}
+		binding := strings.Join(names, ", ")
+		if i == s.selected && s.rebinding {
+			binding = "press a key..."
+		}
+
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%s%-12s %s", cursor, entry.label, binding), x, y)
+		y += 16
+	}
+
+	y += 16
+	ebitenutil.DebugPrintAt(screen, "Up/Down: select  Enter: rebind  Backspace: default  Esc: close", x, y)
+}
@@ -2,8 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	engine "example.com/game/internal"
@@ -28,27 +32,52 @@ const (
 // Client is a middleman between the websocket connection and the hub.
 type Client struct {
 	id   string
-	hub  *Hub
 	conn *websocket.Conn
 	send chan []byte
+
+	// instance is the level the client's unit is on, which changes when it
+	// goes to another one, and closed tells that the client has left. Both
+	// are guarded by mu.
+	mu       sync.Mutex
+	instance *Instance
+	closed   bool
+
+	// room is where the client took a seat.
+	room *Room
+
+	// ack is the tick of the last snapshot the client has received.
+	ack atomic.Uint64
+
+	// history holds the snapshots recently sent to the client, by tick.
+	// It is only touched by the hub.
+	history map[uint64]*engine.EventSnapshot
 }
 
-func (c *Client) readPump(world *engine.World) {
+// current returns the instance the client is on.
+func (c *Client) current() *Instance {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.instance
+}
+
+func (c *Client) readPump(lobby *Lobby) {
 	defer func() {
-		event := &engine.Event{
+		c.mu.Lock()
+		c.closed = true
+		instance := c.instance
+		c.mu.Unlock()
+
+		// The unit leaves the view of other players with the next snapshot.
+		instance.world.HandleEvent(&engine.Event{
 			Type: engine.Event_type_exit,
 			Data: &engine.Event_Exit{
 				Exit: &engine.EventExit{PlayerId: c.id},
 			},
-		}
-		message, err := proto.Marshal(event)
-		if err != nil {
-			log.Println(err)
-		}
-		world.HandleEvent(event)
-		c.hub.broadcast <- message
+		})
 
-		c.hub.unregister <- c
+		instance.hub.unregister <- c
+		lobby.leave(c.room)
 		c.conn.Close(websocket.StatusNormalClosure, "")
 	}()
 
@@ -63,16 +92,95 @@ func (c *Client) readPump(world *engine.World) {
 			}
 			break
 		}
-		c.hub.broadcast <- message // ?
 		event := &engine.Event{}
 		err = proto.Unmarshal(message, event)
 		if err != nil {
 			log.Println(err)
+			continue
+		}
+		if data := event.GetAck(); event.GetType() == engine.Event_type_ack && data != nil {
+			// Acknowledgements still on their way from the previous level
+			// are of no use.
+			if data.Level == c.current().name && data.Tick > c.ack.Load() {
+				c.ack.Store(data.Tick)
+			}
+			continue
+		}
+		if !c.intent(event) {
+			log.Printf("client %s: unexpected event %v", c.id, event.GetType())
+			continue
 		}
-		world.HandleEvent(event)
+		c.current().world.HandleEvent(event)
 	}
 }
 
+// intent checks that the event is an input intent and binds it to the
+// client's own unit. Clients are not trusted with anything else: the
+// resulting positions reach them through snapshots.
+func (c *Client) intent(event *engine.Event) bool {
+	switch event.GetType() {
+	case engine.Event_type_move:
+		if event.GetMove() == nil {
+			return false
+		}
+		event.GetMove().PlayerId = c.id
+
+	case engine.Event_type_idle:
+		if event.GetIdle() == nil {
+			return false
+		}
+		event.GetIdle().PlayerId = c.id
+
+	case engine.Event_type_attack:
+		if event.GetAttack() == nil {
+			return false
+		}
+		event.GetAttack().PlayerId = c.id
+
+	case engine.Event_type_use:
+		if event.GetUse() == nil {
+			return false
+		}
+		event.GetUse().PlayerId = c.id
+
+	case engine.Event_type_interact:
+		if event.GetInteract() == nil {
+			return false
+		}
+		event.GetInteract().PlayerId = c.id
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// update encodes the client's view of a snapshot: a delta against the
+// last view it acknowledged, or the whole view when that one is no longer
+// in the history.
+func (c *Client) update(snapshot *engine.EventSnapshot) ([]byte, error) {
+	c.history[snapshot.Tick] = snapshot
+	for tick := range c.history {
+		if tick+historySize*engine.TickRate/engine.SnapshotRate <= snapshot.Tick {
+			delete(c.history, tick)
+		}
+	}
+
+	event := &engine.Event{
+		Type: engine.Event_type_snapshot,
+		Data: &engine.Event_Snapshot{Snapshot: snapshot},
+	}
+	if base, ok := c.history[c.ack.Load()]; ok {
+		event = &engine.Event{
+			Type: engine.Event_type_delta,
+			Data: &engine.Event_Delta{Delta: engine.Diff(base, snapshot, c.id)},
+		}
+	}
+
+	return proto.Marshal(event)
+}
+
 // writePump pumps messages from the hub to the websocket connection.
 //
 // A goroutine running writePump is started for each connection. The
@@ -123,23 +231,69 @@ func (c *Client) writePump() {
 }
 
 // serveWs handles websocket requests from the peer.
-func serveWs(hub *Hub, world *engine.World, w http.ResponseWriter, r *http.Request) {
+// The player joins the room whose code is in the "room" query parameter, or
+// one the matchmaker picks without it.
+func serveWs(lobby *Lobby, w http.ResponseWriter, r *http.Request) {
+	room, err := lobby.join(r.URL.Query().Get("room"), remoteHost(r))
+	switch {
+	case errors.Is(err, errNoRoom):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, errRoomFull):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, errTooManyRooms):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case errors.Is(err, errTooSoon):
+		w.Header().Set("Retry-After", strconv.Itoa(int(roomCreateInterval/time.Second)))
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	case err != nil:
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	instance, err := room.levels.get(room.levels.first)
+	if err != nil {
+		lobby.leave(room)
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	conn, err := websocket.Accept(w, r, nil)
 	if err != nil {
+		lobby.leave(room)
 		log.Println(err)
 		return
 	}
 
-	id := world.AddPlayer()
-	client := &Client{id: id, hub: hub, conn: conn, send: make(chan []byte, 256)}
-	client.hub.register <- client
+	world := instance.world
+	unit := world.AddPlayer()
+	client := &Client{
+		id:       unit.Id,
+		conn:     conn,
+		send:     make(chan []byte, 256),
+		instance: instance,
+		room:     room,
+		history:  make(map[uint64]*engine.EventSnapshot),
+	}
+	instance.hub.register <- client
 
+	// The new unit is only part of the world after the next step.
+	snapshot := world.Snapshot()
+	snapshot.Units[unit.Id] = unit
+	view := engine.Interest(snapshot, engine.NewGrid(snapshot.Units, engine.InterestRadius), unit.Id)
 	event := &engine.Event{
 		Type: engine.Event_type_init,
 		Data: &engine.Event_Init{
 			Init: &engine.EventInit{
-				PlayerId: id,
-				Units:    world.Units,
+				PlayerId: unit.Id,
+				Units:    view.Units,
+				Level:    world.Level.Name,
+				Seed:     world.Level.Seed,
 			},
 		},
 	}
@@ -158,22 +312,11 @@ func serveWs(hub *Hub, world *engine.World, w http.ResponseWriter, r *http.Reque
 		log.Println(err)
 	}
 
-	unit := world.Units[id]
-	event = &engine.Event{
-		Type: engine.Event_type_connect,
-		Data: &engine.Event_Connect{
-			Connect: &engine.EventConnect{Unit: unit},
-		},
-	}
-	message, err = proto.Marshal(event)
-	if err != nil {
-		//todo: remove unit
-		log.Println(err)
-	}
-	hub.broadcast <- message
+	// Other players are told about the new unit by their next snapshot,
+	// once it is close enough for them to care.
 
 	// Allow collection of memory referenced by the caller by doing all work
 	// in new goroutines.
 	go client.writePump()
-	go client.readPump(world)
+	go client.readPump(lobby)
 }
@@ -1,19 +1,46 @@
 package main
 
+import (
+	"log"
+
+	engine "example.com/game/internal"
+	"github.com/golang/protobuf/proto"
+)
+
+// historySize is how many past snapshots are kept per client as delta
+// bases. Clients that have not acknowledged any of them get a full one.
+const historySize = 2 * engine.SnapshotRate
+
 // Hub maintains the set of active clients and broadcasts messages
 // to the clients.
 type Hub struct {
 	clients    map[*Client]bool
 	broadcast  chan []byte
+	snapshot   chan *engine.EventSnapshot
 	register   chan *Client
 	unregister chan *Client
+	transfer   chan *Transfer
+
+	// done stops the hub when it is closed.
+	done <-chan struct{}
+}
+
+// Transfer is a unit that went from one instance to another, whose client
+// follows it there.
+type Transfer struct {
+	unit *engine.Unit
+	from *Instance
+	to   *Instance
 }
 
-func newHub() *Hub {
+func newHub(done <-chan struct{}) *Hub {
 	return &Hub{
+		done:       done,
 		broadcast:  make(chan []byte, 1),
+		snapshot:   make(chan *engine.EventSnapshot, 1),
 		register:   make(chan *Client, 1),
 		unregister: make(chan *Client, 1),
+		transfer:   make(chan *Transfer, 1),
 		clients:    make(map[*Client]bool),
 	}
 }
@@ -21,6 +48,8 @@ func newHub() *Hub {
 func (h *Hub) run() {
 	for {
 		select {
+		case <-h.done:
+			return
 		case client := <-h.register:
 			h.clients[client] = true
 		case client := <-h.unregister:
@@ -28,15 +57,92 @@ func (h *Hub) run() {
 				delete(h.clients, client)
 				close(client.send)
 			}
+		case transfer := <-h.transfer:
+			h.hand(transfer)
 		case message := <-h.broadcast:
 			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
+				h.deliver(client, message)
+			}
+		case snapshot := <-h.snapshot:
+			// Every client only hears about the units around its own.
+			grid := engine.NewGrid(snapshot.Units, engine.InterestRadius)
+			for client := range h.clients {
+				view := engine.Interest(snapshot, grid, client.id)
+				message, err := client.update(view)
+				if err != nil {
+					log.Println(err)
+					continue
 				}
+				h.deliver(client, message)
 			}
 		}
 	}
 }
+
+// deliver queues a message for the client, dropping the client if it does
+// not keep up.
+func (h *Hub) deliver(client *Client, message []byte) {
+	select {
+	case client.send <- message:
+	default:
+		close(client.send)
+		delete(h.clients, client)
+	}
+}
+
+// hand moves the client of a unit that went to another level over to the
+// hub there, and puts the unit into that world unless the client is gone.
+// The client is told to load the level with a new init event, and starts
+// over with full snapshots.
+func (h *Hub) hand(transfer *Transfer) {
+	var client *Client
+	for c := range h.clients {
+		if c.id == transfer.unit.Id {
+			client = c
+			break
+		}
+	}
+	if client == nil {
+		return
+	}
+	delete(h.clients, client)
+
+	event := &engine.Event{
+		Type: engine.Event_type_init,
+		Data: &engine.Event_Init{
+			Init: &engine.EventInit{
+				PlayerId: client.id,
+				Units:    map[string]*engine.Unit{client.id: proto.Clone(transfer.unit).(*engine.Unit)},
+				Level:    transfer.to.world.Level.Name,
+				Seed:     transfer.to.world.Level.Seed,
+			},
+		},
+	}
+	message, err := proto.Marshal(event)
+	if err != nil {
+		log.Println(err)
+		close(client.send)
+		return
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if client.closed {
+		// Its unregister finds it gone, so its writePump is stopped here.
+		close(client.send)
+		return
+	}
+	// The exit of a client leaving from now on goes to the new world,
+	// after its arrival.
+	transfer.to.world.Arrive(transfer.unit, transfer.from.name)
+	client.instance = transfer.to
+	client.history = make(map[uint64]*engine.EventSnapshot)
+	client.ack.Store(0)
+
+	select {
+	case client.send <- message:
+		go func() { transfer.to.hub.register <- client }()
+	default:
+		close(client.send)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	engine "example.com/game/internal"
+	"github.com/golang/protobuf/proto"
+)
+
+// Instance is a level being played: its world, which ticks on its own, and
+// the hub of the clients on it.
+type Instance struct {
+	name  string
+	world *engine.World
+	hub   *Hub
+}
+
+// Levels hosts an instance of every level players have gone to. Instances
+// are started the first time someone goes there and keep running.
+type Levels struct {
+	// seed generates the floors of the dungeon, named by their depth: floor
+	// n gets seed+n-1, skipping 0. Without a seed, levels are loaded from
+	// asset/levels by name.
+	seed  int64
+	first string
+
+	footprints map[string]image.Rectangle
+
+	mu        sync.Mutex
+	instances map[string]*Instance
+
+	// done stops every instance when it is closed.
+	done chan struct{}
+}
+
+func newLevels(first string, seed int64, footprints map[string]image.Rectangle) *Levels {
+	return &Levels{
+		seed:       seed,
+		first:      first,
+		footprints: footprints,
+		instances:  map[string]*Instance{},
+		done:       make(chan struct{}),
+	}
+}
+
+// stop ends the simulation of every level. It must only be called once
+// nobody plays on them anymore.
+func (levels *Levels) stop() {
+	close(levels.done)
+}
+
+// floorPrefix starts the names of the generated floors, which end in their
+// depth from 1.
+const floorPrefix = "dungeon_"
+
+func floorName(depth int) string {
+	return floorPrefix + strconv.Itoa(depth)
+}
+
+// get returns the instance of a level, starting it if it is not running
+// yet. It is safe to call from any goroutine.
+func (levels *Levels) get(name string) (*Instance, error) {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	if instance, ok := levels.instances[name]; ok {
+		return instance, nil
+	}
+
+	level, err := levels.open(name)
+	if err != nil {
+		return nil, err
+	}
+	instance := &Instance{
+		name: name,
+		world: &engine.World{
+			Replica:    false,
+			Units:      map[string]*engine.Unit{},
+			Level:      level,
+			Footprints: levels.footprints,
+			Seed:       time.Now().UnixNano(),
+		},
+		hub: newHub(levels.done),
+	}
+	levels.instances[name] = instance
+	log.Printf("level %s started", name)
+
+	go instance.hub.run()
+	go simulate(levels, instance)
+
+	return instance, nil
+}
+
+// open builds the level with the given name.
+func (levels *Levels) open(name string) (*engine.Level, error) {
+	if levels.seed == 0 {
+		return engine.LoadLevel(name)
+	}
+
+	depth, err := strconv.Atoi(strings.TrimPrefix(name, floorPrefix))
+	if err != nil || depth < 1 || !strings.HasPrefix(name, floorPrefix) {
+		return nil, fmt.Errorf("level %s: not a floor of the dungeon", name)
+	}
+	up := ""
+	if depth > 1 {
+		up = floorName(depth - 1)
+	}
+	return engine.Generate(name, levels.floorSeed(depth), up, floorName(depth+1)), nil
+}
+
+// floorSeed returns the seed of the floor at the depth. Clients load the
+// level from a file when the seed is 0, so the floors below a negative seed
+// skip it.
+func (levels *Levels) floorSeed(depth int) int64 {
+	seed := levels.seed + int64(depth-1)
+	if levels.seed < 0 && seed >= 0 {
+		seed++
+	}
+	return seed
+}
+
+// move takes a unit that left one instance to the level it went to. The
+// hub the client of the unit is on hands it over. Units going nowhere come
+// back where they left.
+func (levels *Levels) move(from *Instance, departure engine.Departure) {
+	to, err := levels.get(departure.Level)
+	if err != nil {
+		log.Println(err)
+		from.world.Arrive(departure.Unit, departure.Level)
+		return
+	}
+
+	from.hub.transfer <- &Transfer{unit: departure.Unit, from: from, to: to}
+}
+
+// simulate runs the authoritative simulation of an instance at
+// engine.TickRate and hands its state to the hub engine.SnapshotRate times
+// per second, along with the events every player on it should hear about.
+// Replicas reconcile to it, so this is the only source of truth for unit
+// positions.
+func simulate(levels *Levels, instance *Instance) {
+	world, hub := instance.world, instance.hub
+	ticker := time.NewTicker(time.Second / engine.TickRate)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-levels.done:
+			return
+		case <-ticker.C:
+		}
+
+		world.Step(world.Tick + 1)
+		for _, event := range world.Events() {
+			message, err := proto.Marshal(event)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			select {
+			case hub.broadcast <- message:
+			case <-levels.done:
+				return
+			}
+		}
+		for _, departure := range world.Departures() {
+			levels.move(instance, departure)
+		}
+		if world.Tick%(engine.TickRate/engine.SnapshotRate) == 0 {
+			select {
+			case hub.snapshot <- world.Snapshot():
+			case <-levels.done:
+				return
+			}
+		}
+	}
+}
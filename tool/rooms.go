@@ -0,0 +1,375 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRoomPlayers is how many players fit in a room unless its creator
+// says otherwise, up to MaxRoomPlayers.
+const DefaultRoomPlayers = 4
+const MaxRoomPlayers = 16
+
+// MaxRooms is how many rooms the server keeps open at once, and
+// roomCreateInterval how long a client has to wait between creating two.
+const MaxRooms = 64
+const roomCreateInterval = 10 * time.Second
+
+// emptyRoomTimeout is how long a room is kept without players, so its
+// creator has time to join and friends to come back.
+const emptyRoomTimeout = time.Minute
+
+// codeLetters make up room codes. Letters and digits that look alike are
+// left out, so codes are easy to read out.
+const codeLetters = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+const codeLength = 5
+
+var errNoRoom = errors.New("no such room")
+var errRoomFull = errors.New("room is full")
+var errTooManyRooms = errors.New("too many rooms")
+var errTooSoon = errors.New("rooms are created too often")
+
+// Room is a group of players sharing a dungeon of their own. Public rooms
+// are listed and filled by the matchmaker; private ones are only joined by
+// their code.
+type Room struct {
+	code     string
+	public   bool
+	capacity int
+	levels   *Levels
+
+	// players is how many clients are in the room, and empty since when
+	// there has been none. Both are guarded by the mutex of the lobby.
+	players int
+	empty   time.Time
+}
+
+// RoomInfo is how rooms are listed.
+type RoomInfo struct {
+	Code    string `json:"code"`
+	Players int    `json:"players"`
+	Max     int    `json:"max"`
+}
+
+// Lobby keeps the rooms of the server.
+type Lobby struct {
+	// level is the level players start on when levels are loaded from
+	// asset/levels, and seed the seed of the dungeon of every room when it
+	// is fixed. Otherwise every room gets a fresh dungeon.
+	level      string
+	seed       int64
+	footprints map[string]image.Rectangle
+
+	mu    sync.Mutex
+	rooms map[string]*Room
+
+	// created is when every client last created a room, by address.
+	created map[string]time.Time
+}
+
+func newLobby(level string, seed int64, footprints map[string]image.Rectangle) *Lobby {
+	return &Lobby{
+		level:      level,
+		seed:       seed,
+		footprints: footprints,
+		rooms:      map[string]*Room{},
+		created:    map[string]time.Time{},
+	}
+}
+
+// create opens a room for at most capacity players, the default for 0,
+// on behalf of the client at the address.
+func (lobby *Lobby) create(public bool, capacity int, addr string) (*Room, error) {
+	if capacity == 0 {
+		capacity = DefaultRoomPlayers
+	}
+	if capacity < 1 || capacity > MaxRoomPlayers {
+		return nil, fmt.Errorf("rooms hold 1 to %d players", MaxRoomPlayers)
+	}
+
+	lobby.mu.Lock()
+	err := lobby.admit(addr)
+	lobby.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	room, err := lobby.build(public, capacity)
+	if err != nil {
+		return nil, err
+	}
+
+	lobby.mu.Lock()
+	defer lobby.mu.Unlock()
+
+	if err := lobby.add(room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// admit checks, with the lobby locked, that the client at the address may
+// have a room built for it, and counts it if so.
+func (lobby *Lobby) admit(addr string) error {
+	if len(lobby.rooms) >= MaxRooms {
+		return errTooManyRooms
+	}
+	if time.Since(lobby.created[addr]) < roomCreateInterval {
+		return errTooSoon
+	}
+	lobby.created[addr] = time.Now()
+
+	return nil
+}
+
+// build makes a room and starts its first level. Generating the dungeon
+// takes a while, so the lobby must not be locked.
+func (lobby *Lobby) build(public bool, capacity int) (*Room, error) {
+	var levels *Levels
+	if lobby.level != "" {
+		levels = newLevels(lobby.level, 0, lobby.footprints)
+	} else {
+		seed := lobby.seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		levels = newLevels(floorName(1), seed, lobby.footprints)
+	}
+	if _, err := levels.get(levels.first); err != nil {
+		levels.stop()
+		return nil, err
+	}
+
+	return &Room{
+		public:   public,
+		capacity: capacity,
+		levels:   levels,
+		empty:    time.Now(),
+	}, nil
+}
+
+// add gives a built room a code and opens it, with the lobby locked. The
+// room is stopped if there are too many already.
+func (lobby *Lobby) add(room *Room) error {
+	if len(lobby.rooms) >= MaxRooms {
+		room.levels.stop()
+		return errTooManyRooms
+	}
+
+	room.code = newCode()
+	for lobby.rooms[room.code] != nil {
+		room.code = newCode()
+	}
+	lobby.rooms[room.code] = room
+	log.Printf("room %s opened for %d players", room.code, room.capacity)
+
+	return nil
+}
+
+func newCode() string {
+	code := make([]byte, codeLength)
+	for i := range code {
+		code[i] = codeLetters[rand.Intn(len(codeLetters))]
+	}
+	return string(code)
+}
+
+// join takes a seat in the room with the given code, or in the room the
+// matchmaker picks without one for the client at the address. Every join
+// must be followed by a leave.
+func (lobby *Lobby) join(code, addr string) (*Room, error) {
+	if code == "" {
+		return lobby.match(addr)
+	}
+
+	lobby.mu.Lock()
+	defer lobby.mu.Unlock()
+
+	room := lobby.rooms[strings.ToUpper(code)]
+	if room == nil {
+		return nil, errNoRoom
+	}
+	if room.players >= room.capacity {
+		return nil, errRoomFull
+	}
+	room.players++
+
+	return room, nil
+}
+
+// match seats a player in the fullest public room that has space left, so
+// rooms fill up before new ones are opened, or opens a new one if there is
+// none. Opening one counts against the rooms of the server and of the
+// client at the address like create does.
+func (lobby *Lobby) match(addr string) (*Room, error) {
+	lobby.mu.Lock()
+	if best := lobby.pick(); best != nil {
+		best.players++
+		lobby.mu.Unlock()
+		return best, nil
+	}
+	err := lobby.admit(addr)
+	lobby.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	room, err := lobby.build(true, DefaultRoomPlayers)
+	if err != nil {
+		return nil, err
+	}
+
+	lobby.mu.Lock()
+	defer lobby.mu.Unlock()
+
+	// Someone else may have opened a room in the meantime.
+	if best := lobby.pick(); best != nil {
+		room.levels.stop()
+		best.players++
+		return best, nil
+	}
+	if err := lobby.add(room); err != nil {
+		return nil, err
+	}
+	room.players++
+
+	return room, nil
+}
+
+// pick returns the room the matchmaker would seat a player in, with the
+// lobby locked, or nil if every public room is full.
+func (lobby *Lobby) pick() *Room {
+	var best *Room
+	for _, room := range lobby.rooms {
+		if !room.public || room.players >= room.capacity {
+			continue
+		}
+		if best == nil || room.players > best.players || room.players == best.players && room.code < best.code {
+			best = room
+		}
+	}
+	return best
+}
+
+// leave gives up a seat taken with join.
+func (lobby *Lobby) leave(room *Room) {
+	lobby.mu.Lock()
+	defer lobby.mu.Unlock()
+
+	room.players--
+	if room.players == 0 {
+		room.empty = time.Now()
+	}
+}
+
+// list returns the public rooms that have space left.
+func (lobby *Lobby) list() []RoomInfo {
+	lobby.mu.Lock()
+	defer lobby.mu.Unlock()
+
+	rooms := []RoomInfo{}
+	for _, room := range lobby.rooms {
+		if room.public && room.players < room.capacity {
+			rooms = append(rooms, RoomInfo{Code: room.code, Players: room.players, Max: room.capacity})
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].Code < rooms[j].Code
+	})
+
+	return rooms
+}
+
+// reap closes the rooms that have stayed empty for too long and stops
+// their levels.
+func (lobby *Lobby) reap() {
+	ticker := time.NewTicker(emptyRoomTimeout / 4)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		lobby.mu.Lock()
+		for code, room := range lobby.rooms {
+			if room.players == 0 && time.Since(room.empty) > emptyRoomTimeout {
+				delete(lobby.rooms, code)
+				room.levels.stop()
+				log.Printf("room %s closed", code)
+			}
+		}
+		for addr, at := range lobby.created {
+			if time.Since(at) >= roomCreateInterval {
+				delete(lobby.created, addr)
+			}
+		}
+		lobby.mu.Unlock()
+	}
+}
+
+// serveRooms lists the public rooms on GET and opens a room on POST, for
+// at most the players of the "max" form value, listed unless "private" is
+// set.
+func serveRooms(lobby *Lobby, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(lobby.list()); err != nil {
+			log.Println(err)
+		}
+
+	case http.MethodPost:
+		capacity := 0
+		if value := r.FormValue("max"); value != "" {
+			var err error
+			capacity, err = strconv.Atoi(value)
+			if err != nil {
+				http.Error(w, "max is not a number", http.StatusBadRequest)
+				return
+			}
+		}
+		private, _ := strconv.ParseBool(r.FormValue("private"))
+
+		room, err := lobby.create(!private, capacity, remoteHost(r))
+		switch {
+		case errors.Is(err, errTooManyRooms):
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		case errors.Is(err, errTooSoon):
+			w.Header().Set("Retry-After", strconv.Itoa(int(roomCreateInterval/time.Second)))
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		err = json.NewEncoder(w).Encode(RoomInfo{Code: room.code, Max: room.capacity})
+		if err != nil {
+			log.Println(err)
+		}
+
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// remoteHost returns the address of the client of the request without its
+// port.
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
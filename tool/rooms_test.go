@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestCreateLimits(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	lobby := newLobby("", 1, nil)
+	defer func() {
+		for _, room := range lobby.rooms {
+			room.levels.stop()
+		}
+	}()
+
+	if _, err := lobby.create(true, MaxRoomPlayers+1, "a"); err == nil {
+		t.Error("created a room for too many players")
+	}
+	if _, err := lobby.create(false, 0, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lobby.create(false, 0, "a"); !errors.Is(err, errTooSoon) {
+		t.Errorf("second room right away: got %v, want %v", err, errTooSoon)
+	}
+
+	for n := len(lobby.rooms); n < MaxRooms; n++ {
+		lobby.rooms[strconv.Itoa(n)] = &Room{capacity: 1, players: 1, levels: newLevels("", 0, nil)}
+	}
+	if _, err := lobby.create(true, 0, "b"); !errors.Is(err, errTooManyRooms) {
+		t.Errorf("room over the limit: got %v, want %v", err, errTooManyRooms)
+	}
+	if _, err := lobby.match("c"); !errors.Is(err, errTooManyRooms) {
+		t.Errorf("matched over the limit: got %v, want %v", err, errTooManyRooms)
+	}
+}
+
+func TestMatchFillsRooms(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	lobby := newLobby("", 1, nil)
+	defer func() {
+		for _, room := range lobby.rooms {
+			room.levels.stop()
+		}
+	}()
+
+	first, err := lobby.match("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := 1; n < DefaultRoomPlayers; n++ {
+		room, err := lobby.match("a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if room != first {
+			t.Fatalf("player %d got room %s, want %s", n+1, room.code, first.code)
+		}
+	}
+
+	if _, err := lobby.match("a"); !errors.Is(err, errTooSoon) {
+		t.Errorf("second room right away: got %v, want %v", err, errTooSoon)
+	}
+	room, err := lobby.match("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if room == first || len(lobby.rooms) != 2 {
+		t.Errorf("full room was not left alone: %d rooms", len(lobby.rooms))
+	}
+}
@@ -28,15 +28,6 @@ const reloadScript = `
 
 var waitCh = make(chan struct{})
 
-var world *engine.World
-
-func init() {
-	world = &engine.World{
-		Replica: false,
-		Units:   map[string]*engine.Unit{},
-	}
-}
-
 func serve(args []string) error {
 	// Parse flags
 	flag := flag.NewFlagSet("serve", flag.ExitOnError)
@@ -52,15 +43,29 @@ func serve(args []string) error {
 	delay := flag.Int("delay", 0, "Delay for displaying a loading UI")
 	addr := flag.String("http", APP_IP+":"+APP_PORT, "HTTP service address")
 	allowOrigin := flag.String("allow-origin", "*", "Allowed origin for CORS requests")
+	levelName := flag.String("level", "", "Level to start on from asset/levels instead of generated floors")
+	seed := flag.Int64("seed", 0, "Seed of the generated floors of every room, fresh for each room when 0")
 	flag.Parse(args)
 
 	if flag.NArg() > 0 {
 		fmt.Fprintln(os.Stderr, "Unexpected arguments:", flag.Args())
 		flag.Usage()
 	}
-	go world.Evolve()
-	hub := newHub()
-	go hub.run()
+	footprints, err := engine.LoadFootprints()
+	if err != nil {
+		return err
+	}
+
+	// Every room plays on its own dungeon, generated from the seed or a
+	// fresh one, or starts on the given level.
+	if *levelName != "" {
+		if _, err := engine.LoadLevel(*levelName); err != nil {
+			return err
+		}
+	}
+	lobby := newLobby(*levelName, *seed, footprints)
+	go lobby.reap()
+
 	// Register handler
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 
@@ -125,7 +130,13 @@ func serve(args []string) error {
 	// Open browser if possible.
 
 	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		serveWs(hub, world, w, r)
+		serveWs(lobby, w, r)
+	})
+	http.HandleFunc("/rooms", func(w http.ResponseWriter, r *http.Request) {
+		if *allowOrigin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", *allowOrigin)
+		}
+		serveRooms(lobby, w, r)
 	})
 	return http.ListenAndServe(*addr, nil)
 }
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	e "github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// stickRadius is how far the knob of the virtual joystick travels from its
+// base; pushing it all the way runs at full speed.
+const stickRadius = 48
+
+const buttonRadius = 32
+
+// touchButton is an on-screen button, placed relative to the bottom right
+// corner of the screen.
+type touchButton struct {
+	label   string
+	right   float64
+	bottom  float64
+	pressed bool
+}
+
+// TouchControls are the virtual joystick and action buttons for touch
+// screens. They stay hidden until the first touch, so desktop players
+// never see them.
+type TouchControls struct {
+	visible bool
+	width   float64
+	height  float64
+
+	// The joystick follows the finger that first touched the left half of
+	// the screen, with its base where that finger landed.
+	holding bool
+	stick   e.TouchID
+	baseX   float64
+	baseY   float64
+	knobX   float64
+	knobY   float64
+
+	attack   touchButton
+	interact touchButton
+
+	// use is the inventory slot tapped in this tick, counted from 1.
+	use int
+}
+
+var touch = &TouchControls{
+	attack:   touchButton{label: "ATK", right: 64, bottom: 64},
+	interact: touchButton{label: "USE", right: 144, bottom: 96},
+}
+
+// Update follows the touches of this tick on a screen of the given size.
+func (t *TouchControls) Update(width, height int) {
+	t.width, t.height = float64(width), float64(height)
+
+	t.use = 0
+	for _, id := range inpututil.AppendJustPressedTouchIDs(nil) {
+		t.visible = true
+		x, y := e.TouchPosition(id)
+		if slot := slotAt(x, y, t.width, t.height); slot >= 0 {
+			t.use = slot + 1
+			continue
+		}
+		if !t.holding && float64(x) < t.width/2 {
+			t.holding = true
+			t.stick = id
+			t.baseX, t.baseY = float64(x), float64(y)
+		}
+	}
+
+	if t.holding {
+		if inpututil.IsTouchJustReleased(t.stick) {
+			t.holding = false
+		} else {
+			x, y := e.TouchPosition(t.stick)
+			dx, dy := float64(x)-t.baseX, float64(y)-t.baseY
+			if d := math.Hypot(dx, dy); d > stickRadius {
+				dx, dy = dx/d*stickRadius, dy/d*stickRadius
+			}
+			t.knobX, t.knobY = t.baseX+dx, t.baseY+dy
+		}
+	}
+
+	t.attack.pressed = false
+	t.interact.pressed = false
+	for _, id := range e.AppendTouchIDs(nil) {
+		if t.holding && id == t.stick {
+			continue
+		}
+		x, y := e.TouchPosition(id)
+		for _, button := range []*touchButton{&t.attack, &t.interact} {
+			bx, by := t.center(button)
+			if math.Hypot(float64(x)-bx, float64(y)-by) <= buttonRadius {
+				button.pressed = true
+			}
+		}
+	}
+}
+
+// read adds what the touches ask for to the intent.
+func (t *TouchControls) read(intent *Intent) {
+	if t.holding {
+		intent.add((t.knobX-t.baseX)/stickRadius, (t.knobY-t.baseY)/stickRadius)
+	}
+	if t.attack.pressed {
+		intent.Attack = true
+	}
+	if t.interact.pressed {
+		intent.Interact = true
+	}
+	if t.use != 0 {
+		intent.Use = t.use
+	}
+}
+
+// Draw draws the controls on top of the game once touches were seen.
+func (t *TouchControls) Draw(screen *e.Image) {
+	if !t.visible {
+		return
+	}
+
+	idle := color.RGBA{0xff, 0xff, 0xff, 0x30}
+	active := color.RGBA{0xff, 0xff, 0xff, 0x70}
+
+	baseX, baseY := t.baseX, t.baseY
+	knobX, knobY := t.knobX, t.knobY
+	if !t.holding {
+		baseX, baseY = stickRadius*2, t.height-stickRadius*2
+		knobX, knobY = baseX, baseY
+	}
+	vector.StrokeCircle(screen, float32(baseX), float32(baseY), stickRadius, 2, idle, true)
+	vector.DrawFilledCircle(screen, float32(knobX), float32(knobY), stickRadius/2, active, true)
+
+	for _, button := range []*touchButton{&t.attack, &t.interact} {
+		x, y := t.center(button)
+		clr := idle
+		if button.pressed {
+			clr = active
+		}
+		vector.DrawFilledCircle(screen, float32(x), float32(y), buttonRadius, clr, true)
+		ebitenutil.DebugPrintAt(screen, button.label, int(x)-len(button.label)*3, int(y)-8)
+	}
+}
+
+func (t *TouchControls) center(button *touchButton) (float64, float64) {
+	return t.width - button.right, t.height - button.bottom
+}